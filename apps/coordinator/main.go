@@ -1,15 +1,38 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"os"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 
 	pb "github.com/skewer-project/skewer/api/proto/coordinator/v1"
 	"github.com/skewer-project/skewer/internal/coordinator"
 )
 
+// newScheduler picks the Scheduler backend via SKEWER_QUEUE_BACKEND so a
+// local/dev coordinator can stay on the in-memory channel-based scheduler
+// without a Redis instance, while a deployed one durably persists its queue.
+// Defaults to "memory".
+func newScheduler(tracker *coordinator.JobTracker) coordinator.Scheduler {
+	switch os.Getenv("SKEWER_QUEUE_BACKEND") {
+	case "redis":
+		rdb := redis.NewClient(&redis.Options{Addr: os.Getenv("SKEWER_REDIS_ADDR")})
+		scheduler := coordinator.NewRedisScheduler(rdb, coordinator.DefaultLeaseDuration)
+		go scheduler.StartLeaseSweeper(context.Background(), 30*time.Second)
+		return scheduler
+	default:
+		scheduler := coordinator.NewMemoryScheduler(1024)
+		scheduler.SetJobGraph(tracker.Graph())
+		go scheduler.StartRescorer(context.Background(), 5*time.Second)
+		return scheduler
+	}
+}
+
 func main() {
 	// Listen on a TCP port
 	lis, err := net.Listen("tcp", ":50051")
@@ -18,9 +41,27 @@ func main() {
 	}
 	log.Printf("Coordinator listening on :50051")
 
-	grpcServer := grpc.NewServer() // Generic gRPC server
+	store, err := coordinator.NewFSJobStore("./data")
+	if err != nil {
+		log.Fatalf("[Error] Failed to open job store: %v", err)
+	}
+
+	tracker := coordinator.NewJobTracker(store)
+	var manager coordinator.CloudManager
 
-	myServer := coordinator.NewServer() // Logical server
+	registry := coordinator.NewWorkerRegistry()
+	scheduler := newScheduler(tracker)
+
+	myServer := coordinator.NewServer(scheduler, &manager, tracker, registry) // Logical server
+
+	if err := myServer.Replay(); err != nil {
+		log.Fatalf("[Error] Failed to replay job store: %v", err)
+	}
+
+	go myServer.StartSessionReaper(context.Background(), coordinator.HeartbeatInterval)
+	go myServer.StartReconciler(context.Background(), coordinator.ReconcileInterval)
+
+	grpcServer := grpc.NewServer() // Generic gRPC server
 
 	// Register logical server with gRPC engine
 	pb.RegisterCoordinatorServiceServer(grpcServer, myServer)
@@ -0,0 +1,246 @@
+package coordinator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	pb "github.com/skewer-project/skewer/api/proto/coordinator/v1"
+)
+
+// TestCrashMidJob_ReplayResumesWithoutDuplicateCompletion kills a "coordinator"
+// mid-job - by throwing away its JobTracker and MemoryScheduler, the way a
+// process restart would - after one of a two-task CompositeJob's tasks has
+// already been dispatched to a worker but never acknowledged. It then rebuilds
+// a fresh coordinator against the same FSJobStore and verifies Replay()
+// re-enqueues exactly that one in-flight task, and that both tasks reporting
+// in afterward complete the job exactly once - not twice - per frame.
+func TestCrashMidJob_ReplayResumesWithoutDuplicateCompletion(t *testing.T) {
+	dir, err := os.MkdirTemp("", "skewer-crash-recovery-*")
+	if err != nil {
+		t.Fatalf("failed to create temp store dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFSJobStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSJobStore: %v", err)
+	}
+
+	// === "Coordinator" instance #1, before the crash ===
+	tracker1 := NewJobTracker(store)
+	scheduler1 := NewMemoryScheduler(1024)
+	server1 := NewServer(scheduler1, nil, tracker1, NewWorkerRegistry())
+
+	const jobID = "job-crash-test"
+	submitReq := &pb.SubmitJobRequest{
+		JobId:     jobID,
+		NumFrames: 2,
+		JobType: &pb.SubmitJobRequest_CompositeJob{
+			CompositeJob: &pb.CompositeJobParams{NumTasks: 1},
+		},
+	}
+	if _, err := server1.SubmitJob(context.Background(), submitReq); err != nil {
+		t.Fatalf("SubmitJob: %v", err)
+	}
+
+	worker := NewWorkerProfile("worker-1", []string{"loom"}, 8192, 4, nil, "linux", "amd64", false)
+
+	// Only one of the two tasks ever gets handed to a worker and persisted
+	// as in-flight - mirroring GetWorkStream - before the coordinator dies.
+	// The other is left exactly as EnqueueTask queued it: on disk nowhere,
+	// only in scheduler1's in-memory heap, which the crash will discard.
+	dispatched, err := scheduler1.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask: %v", err)
+	}
+	if err := store.SaveInFlightTask(dispatched); err != nil {
+		t.Fatalf("SaveInFlightTask: %v", err)
+	}
+
+	// === crash: instance #1's JobTracker and Scheduler are gone ===
+
+	// === "Coordinator" instance #2, after the crash ===
+	tracker2 := NewJobTracker(store)
+	scheduler2 := NewMemoryScheduler(1024)
+	server2 := NewServer(scheduler2, nil, tracker2, NewWorkerRegistry())
+
+	if err := server2.Replay(); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	// The never-acknowledged task must have come back - workers resume
+	// instead of that frame stalling forever.
+	if got := scheduler2.GetQueueLength(); got != 1 {
+		t.Fatalf("expected 1 replayed task pending after crash, got %d", got)
+	}
+
+	// Both tasks now flow through instance #2, same as if nothing happened.
+	resumed, err := scheduler2.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask (resumed task): %v", err)
+	}
+	if resumed.ID != dispatched.ID {
+		t.Fatalf("replayed task ID = %s, want the one dispatched before the crash (%s)", resumed.ID, dispatched.ID)
+	}
+
+	second, err := scheduler2.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask (second task): %v", err)
+	}
+
+	for _, task := range []*Task{resumed, second} {
+		resp, err := server2.handleTerminalResult(&pb.TaskResult{
+			TaskId:                 task.ID,
+			JobId:                  jobID,
+			WorkerId:               worker.WorkerID,
+			Success:                true,
+			PrecedingProgressCount: 0,
+		})
+		if err != nil {
+			t.Fatalf("handleTerminalResult(%s): %v", task.ID, err)
+		}
+		if !resp.Acknowledged {
+			t.Fatalf("handleTerminalResult(%s): expected Acknowledged=true", task.ID)
+		}
+	}
+
+	job, err := tracker2.GetJob(jobID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	composite, ok := job.(*CompositeJob)
+	if !ok {
+		t.Fatalf("job %s is a %T, want *CompositeJob", jobID, job)
+	}
+	if composite.CompletedFrames != 2 {
+		t.Fatalf("CompletedFrames = %d, want exactly 2 - the replayed task must not have been counted twice", composite.CompletedFrames)
+	}
+	if got := job.GetStatus(); got != pb.GetJobStatusResponse_JOB_STATUS_COMPLETED {
+		t.Fatalf("job status = %v, want JOB_STATUS_COMPLETED", got)
+	}
+}
+
+// TestCrashMidJob_ReplayResumesRenderFrameWithoutDuplicateChunkCount is the
+// RenderJob counterpart to TestCrashMidJob_ReplayResumesWithoutDuplicateCompletion,
+// covering the exact "per-frame FrameState.CompletedChunks" recovery path
+// chunk0-1 asked for. handleRenderJobSubmit is still an unimplemented stub
+// (see its TODO), so this builds the RenderJob and its RenderTasks directly
+// through JobTracker.AddJob/Scheduler.EnqueueTask instead of going through
+// SubmitJob, the same way a caller will once that stub is filled in.
+func TestCrashMidJob_ReplayResumesRenderFrameWithoutDuplicateChunkCount(t *testing.T) {
+	dir, err := os.MkdirTemp("", "skewer-crash-recovery-render-*")
+	if err != nil {
+		t.Fatalf("failed to create temp store dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFSJobStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSJobStore: %v", err)
+	}
+
+	const jobID = "job-render-crash-test"
+	const frameID = "0"
+
+	// === "Coordinator" instance #1, before the crash ===
+	tracker1 := NewJobTracker(store)
+	scheduler1 := NewMemoryScheduler(1024)
+
+	renderJob := &RenderJob{
+		JobID:          jobID,
+		TotalTasks:     2,
+		SampleDivision: 2,
+		Frames: map[string]*FrameState{
+			frameID: {TotalChunks: 2},
+		},
+		OriginalReq: &pb.SubmitJobRequest{JobId: jobID, Priority: PriorityNormal},
+	}
+	if err := tracker1.AddJob(renderJob); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+
+	if _, err := scheduler1.EnqueueTask(&pb.RenderTask{JobId: jobID, FrameId: frameID}, jobID, frameID, PriorityNormal); err != nil {
+		t.Fatalf("EnqueueTask (chunk 1): %v", err)
+	}
+	if _, err := scheduler1.EnqueueTask(&pb.RenderTask{JobId: jobID, FrameId: frameID}, jobID, frameID, PriorityNormal); err != nil {
+		t.Fatalf("EnqueueTask (chunk 2): %v", err)
+	}
+
+	worker := NewWorkerProfile("worker-1", []string{"skewer"}, 8192, 4, nil, "linux", "amd64", false)
+
+	// Same as the CompositeJob test: only the first chunk is dispatched and
+	// persisted as in-flight before the coordinator dies.
+	dispatched, err := scheduler1.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask: %v", err)
+	}
+	if err := store.SaveInFlightTask(dispatched); err != nil {
+		t.Fatalf("SaveInFlightTask: %v", err)
+	}
+
+	// === crash: instance #1's JobTracker and Scheduler are gone ===
+
+	// === "Coordinator" instance #2, after the crash ===
+	tracker2 := NewJobTracker(store)
+	scheduler2 := NewMemoryScheduler(1024)
+	server2 := NewServer(scheduler2, nil, tracker2, NewWorkerRegistry())
+
+	if err := server2.Replay(); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got := scheduler2.GetQueueLength(); got != 1 {
+		t.Fatalf("expected 1 replayed chunk pending after crash, got %d", got)
+	}
+
+	resumed, err := scheduler2.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask (resumed chunk): %v", err)
+	}
+	if resumed.ID != dispatched.ID {
+		t.Fatalf("replayed chunk ID = %s, want the one dispatched before the crash (%s)", resumed.ID, dispatched.ID)
+	}
+
+	second, err := scheduler2.GetNextTask(context.Background(), worker)
+	if err != nil {
+		t.Fatalf("GetNextTask (second chunk): %v", err)
+	}
+
+	for _, task := range []*Task{resumed, second} {
+		resp, err := server2.handleTerminalResult(&pb.TaskResult{
+			TaskId:                 task.ID,
+			JobId:                  jobID,
+			WorkerId:               worker.WorkerID,
+			Success:                true,
+			PrecedingProgressCount: 0,
+		})
+		if err != nil {
+			t.Fatalf("handleTerminalResult(%s): %v", task.ID, err)
+		}
+		if !resp.Acknowledged {
+			t.Fatalf("handleTerminalResult(%s): expected Acknowledged=true", task.ID)
+		}
+	}
+
+	job, err := tracker2.GetJob(jobID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	render, ok := job.(*RenderJob)
+	if !ok {
+		t.Fatalf("job %s is a %T, want *RenderJob", jobID, job)
+	}
+	if render.CompletedTasks != 2 {
+		t.Fatalf("CompletedTasks = %d, want exactly 2 - the replayed chunk must not have been counted twice", render.CompletedTasks)
+	}
+	frame := render.Frames[frameID]
+	if frame == nil {
+		t.Fatalf("frame %s missing from replayed RenderJob", frameID)
+	}
+	if frame.CompletedChunks != 2 {
+		t.Fatalf("frame %s CompletedChunks = %d, want exactly 2 - the replayed chunk must not have been double-counted", frameID, frame.CompletedChunks)
+	}
+	if got := job.GetStatus(); got != pb.GetJobStatusResponse_JOB_STATUS_COMPLETED {
+		t.Fatalf("job status = %v, want JOB_STATUS_COMPLETED", got)
+	}
+}
@@ -44,6 +44,31 @@ func (d *DAG) GetSuccessors(nodeID string) []string {
 	return d.successors[nodeID]
 }
 
+// GetTransitiveSuccessors walks GetSuccessors breadth-first and returns
+// every node (direct or indirect) that depends, transitively, on nodeID.
+// Used by the scheduler to score how much queued work a task's completion
+// would unblock.
+func (d *DAG) GetTransitiveSuccessors(nodeID string) []string {
+	visited := make(map[string]bool)
+	queue := append([]string{}, d.successors[nodeID]...)
+
+	var result []string
+	for len(queue) > 0 {
+		current, rest := queue[0], queue[1:]
+		queue = rest
+
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		result = append(result, current)
+
+		queue = append(queue, d.successors[current]...)
+	}
+
+	return result
+}
+
 func (d *DAG) TopologicalSort() ([]Node, error) {
 	nodeDegrees := make(map[string]uint) // number of dependencies for a given node id
 
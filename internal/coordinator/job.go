@@ -19,6 +19,13 @@ type Job interface {
 	SetStatus(status pb.GetJobStatusResponse_JobStatus)
 	Progress() float32
 	GetOriginalReq() *pb.SubmitJobRequest
+
+	// GetErrorMessage/SetErrorMessage surface *why* a job landed in a
+	// terminal failure status (e.g. JOB_STATUS_TIMED_OUT cascaded from an
+	// upstream dependency) through GetJobStatus, instead of leaving a
+	// downstream poller to guess.
+	GetErrorMessage() string
+	SetErrorMessage(msg string)
 }
 
 type FrameState struct {
@@ -41,6 +48,10 @@ type RenderJob struct {
 	Frames map[string]*FrameState
 	mu     sync.Mutex // Protects the map during concurrent worker updates
 
+	// ErrorMessage explains a terminal failure (e.g. JOB_STATUS_TIMED_OUT),
+	// surfaced to pollers via GetJobStatus. Empty for jobs that never failed.
+	ErrorMessage string
+
 	OriginalReq *pb.SubmitJobRequest
 }
 
@@ -65,6 +76,18 @@ func (rj *RenderJob) Progress() float32 {
 func (rj *RenderJob) GetOriginalReq() *pb.SubmitJobRequest {
 	return rj.OriginalReq
 }
+func (rj *RenderJob) GetErrorMessage() string {
+	rj.mu.Lock()
+	defer rj.mu.Unlock()
+
+	return rj.ErrorMessage
+}
+func (rj *RenderJob) SetErrorMessage(msg string) {
+	rj.mu.Lock()
+	defer rj.mu.Unlock()
+
+	rj.ErrorMessage = msg
+}
 
 // The Composite Job
 type CompositeJob struct {
@@ -78,6 +101,10 @@ type CompositeJob struct {
 
 	mu sync.Mutex
 
+	// ErrorMessage explains a terminal failure (e.g. JOB_STATUS_TIMED_OUT),
+	// surfaced to pollers via GetJobStatus. Empty for jobs that never failed.
+	ErrorMessage string
+
 	OriginalReq *pb.SubmitJobRequest
 }
 
@@ -102,6 +129,18 @@ func (cj *CompositeJob) Progress() float32 {
 func (rj *CompositeJob) GetOriginalReq() *pb.SubmitJobRequest {
 	return rj.OriginalReq
 }
+func (cj *CompositeJob) GetErrorMessage() string {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+
+	return cj.ErrorMessage
+}
+func (cj *CompositeJob) SetErrorMessage(msg string) {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+
+	cj.ErrorMessage = msg
+}
 
 // ===================== //
 // * Job Tracker Logic * //
@@ -118,13 +157,68 @@ type JobTracker struct {
 
 	// Quick memory access to the actual job payloads
 	activeJobs map[string]Job
+
+	// Write-through persistence so a coordinator restart can Replay() back
+	// to where it left off instead of losing every in-flight job. May be
+	// nil, in which case JobTracker behaves exactly as it always has.
+	store JobStore
+
+	// stalledWork holds downstream enqueue actions (a MergeTask becoming
+	// ready, a dependent job unlocking) that arrived while a job was
+	// paused. ResumeJob flushes these in FIFO order.
+	stalledWork map[string][]func()
+
+	// started marks jobs for which GetWorkStream has handed out at least one
+	// task. Backs the submission timeout: a job still missing from this set
+	// when its timer fires never got picked up by a worker.
+	started map[string]bool
 }
 
-func NewJobTracker() *JobTracker {
+func NewJobTracker(store JobStore) *JobTracker {
 	return &JobTracker{
 		graph:       *NewDAG(), // Initialize the internal DAG
 		pendingDeps: make(map[string]int),
 		activeJobs:  make(map[string]Job),
+		store:       store,
+		stalledWork: make(map[string][]func()),
+		started:     make(map[string]bool),
+	}
+}
+
+// Graph exposes the tracker's DAG so the Scheduler can score how much
+// queued work a task's completion would unblock.
+func (jt *JobTracker) Graph() *DAG {
+	return &jt.graph
+}
+
+// IsPaused reports whether jobID is currently in JOB_STATUS_PAUSED.
+func (jt *JobTracker) IsPaused(jobID string) bool {
+	jt.mu.RLock()
+	defer jt.mu.RUnlock()
+
+	job, exists := jt.activeJobs[jobID]
+	return exists && job.GetStatus() == pb.GetJobStatusResponse_JOB_STATUS_PAUSED
+}
+
+// StallWork records a downstream enqueue action for a paused job to run
+// later, instead of running it immediately.
+func (jt *JobTracker) StallWork(jobID string, fn func()) {
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+
+	jt.stalledWork[jobID] = append(jt.stalledWork[jobID], fn)
+}
+
+// FlushStalledWork runs and discards every action stalled for jobID, in the
+// order they were stalled. Called when a job transitions out of PAUSED.
+func (jt *JobTracker) FlushStalledWork(jobID string) {
+	jt.mu.Lock()
+	fns := jt.stalledWork[jobID]
+	delete(jt.stalledWork, jobID)
+	jt.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
 	}
 }
 
@@ -159,10 +253,138 @@ func (jt *JobTracker) AddJob(job Job) error {
 			jt.graph.AddDependency(job, dep)
 		}
 
+		if jt.store != nil {
+			if err := jt.store.SaveJob(jobRecordFor(job, jt.pendingDeps[job.ID()])); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 }
 
+// Replay rebuilds the DAG, pendingDeps, and per-frame progress from the
+// JobStore. It must be called once on startup, before any SubmitJob or
+// GetWorkStream traffic is accepted. Tasks that were dispatched to a worker
+// but never acknowledged are returned so the caller can re-enqueue them on
+// the Scheduler. pausedJobIDs lists every replayed job whose persisted
+// Status is JOB_STATUS_PAUSED, so the caller can re-arm the Scheduler's
+// pause state too - Replay only restores JobTracker's own bookkeeping, it
+// has no way to reach into the Scheduler itself.
+func (jt *JobTracker) Replay() (inFlight []*TaskRecord, pausedJobIDs []string, err error) {
+	if jt.store == nil {
+		return nil, nil, nil
+	}
+
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+
+	recs, err := jt.store.LoadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[JOBTRACKER] Failed to replay job store: %w", err)
+	}
+
+	// First pass: recreate every job and add it to the graph, without
+	// dependencies, so GetNode succeeds regardless of replay order.
+	for _, rec := range recs {
+		job := jobFromRecord(rec)
+		jt.activeJobs[job.ID()] = job
+		jt.pendingDeps[job.ID()] = rec.PendingDeps
+		jt.graph.AddNode(job)
+		if rec.Status == pb.GetJobStatusResponse_JOB_STATUS_PAUSED {
+			pausedJobIDs = append(pausedJobIDs, job.ID())
+		}
+	}
+
+	// Second pass: wire up dependencies now that every node exists.
+	for _, rec := range recs {
+		job := jt.activeJobs[rec.JobID]
+		for _, depID := range rec.Dependencies {
+			dep, err := jt.graph.GetNode(depID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[JOBTRACKER] Replayed job %s depends on missing job %s", rec.JobID, depID)
+			}
+			jt.graph.AddDependency(job, dep)
+		}
+	}
+
+	taskRecs, err := jt.store.LoadInFlightTasks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[JOBTRACKER] Failed to replay in-flight tasks: %w", err)
+	}
+	return taskRecs, pausedJobIDs, nil
+}
+
+// jobRecordFor snapshots a Job into its persisted form.
+func jobRecordFor(job Job, pendingDeps int) *JobRecord {
+	rec := &JobRecord{
+		JobID:        job.ID(),
+		Status:       job.GetStatus(),
+		Dependencies: job.GetDependencies(),
+		PendingDeps:  pendingDeps,
+		ErrorMessage: job.GetErrorMessage(),
+		OriginalReq:  job.GetOriginalReq(),
+	}
+
+	switch j := job.(type) {
+	case *RenderJob:
+		rec.Kind = "render"
+		rec.CompletedTasks = j.CompletedTasks
+		rec.TotalTasks = j.TotalTasks
+		rec.SampleDivision = j.SampleDivision
+		rec.Frames = make(map[string]*FrameRecord, len(j.Frames))
+		for frameID, fs := range j.Frames {
+			rec.Frames[frameID] = &FrameRecord{
+				CompletedChunks: fs.CompletedChunks,
+				TotalChunks:     fs.TotalChunks,
+				PendingMerge:    fs.PendingMerge,
+			}
+		}
+	case *CompositeJob:
+		rec.Kind = "composite"
+		rec.CompletedFrames = j.CompletedFrames
+		rec.TotalFrames = j.TotalFrames
+	}
+
+	return rec
+}
+
+// jobFromRecord is the inverse of jobRecordFor, used during Replay.
+func jobFromRecord(rec *JobRecord) Job {
+	switch rec.Kind {
+	case "render":
+		frames := make(map[string]*FrameState, len(rec.Frames))
+		for frameID, fr := range rec.Frames {
+			frames[frameID] = &FrameState{
+				CompletedChunks: fr.CompletedChunks,
+				TotalChunks:     fr.TotalChunks,
+				PendingMerge:    fr.PendingMerge,
+			}
+		}
+		return &RenderJob{
+			JobID:          rec.JobID,
+			Dependencies:   rec.Dependencies,
+			Status:         rec.Status,
+			CompletedTasks: rec.CompletedTasks,
+			TotalTasks:     rec.TotalTasks,
+			SampleDivision: rec.SampleDivision,
+			Frames:         frames,
+			ErrorMessage:   rec.ErrorMessage,
+			OriginalReq:    rec.OriginalReq,
+		}
+	default:
+		return &CompositeJob{
+			JobID:           rec.JobID,
+			Dependencies:    rec.Dependencies,
+			Status:          rec.Status,
+			CompletedFrames: rec.CompletedFrames,
+			TotalFrames:     rec.TotalFrames,
+			ErrorMessage:    rec.ErrorMessage,
+			OriginalReq:     rec.OriginalReq,
+		}
+	}
+}
+
 func (jt *JobTracker) GetJob(jobID string) (Job, error) {
 	jt.mu.RLock() // Read lock is a bit faster
 	defer jt.mu.RUnlock()
@@ -174,6 +396,67 @@ func (jt *JobTracker) GetJob(jobID string) (Job, error) {
 	return job, nil
 }
 
+// SetStatus transitions a tracked job's status and writes it through to the
+// JobStore. Callers that need a job's status to survive a crash (pause,
+// resume, timeout, completion, ...) should go through here rather than
+// calling job.SetStatus directly.
+func (jt *JobTracker) SetStatus(jobID string, status pb.GetJobStatusResponse_JobStatus) error {
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+
+	job, exists := jt.activeJobs[jobID]
+	if !exists {
+		return fmt.Errorf("[ERROR] Job with ID %s not found.", jobID)
+	}
+
+	job.SetStatus(status)
+
+	if jt.store != nil {
+		return jt.store.UpdateStatus(jobID, status)
+	}
+	return nil
+}
+
+// MarkStarted records that a worker has pulled at least one task for jobID,
+// disarming its submission timeout. Called from GetWorkStream right after a
+// successful send.
+func (jt *JobTracker) MarkStarted(jobID string) {
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+
+	jt.started[jobID] = true
+}
+
+// WasStarted reports whether MarkStarted has ever been called for jobID.
+func (jt *JobTracker) WasStarted(jobID string) bool {
+	jt.mu.RLock()
+	defer jt.mu.RUnlock()
+
+	return jt.started[jobID]
+}
+
+// Fail transitions jobID to a terminal status with an explanatory message,
+// writing both through to the JobStore. Used by the submission-timeout
+// cascade, and suitable for any other hard-failure path that needs to leave
+// a reason behind for GetJobStatus pollers.
+func (jt *JobTracker) Fail(jobID string, status pb.GetJobStatusResponse_JobStatus, errMsg string) error {
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+
+	job, exists := jt.activeJobs[jobID]
+	if !exists {
+		return fmt.Errorf("[ERROR] Job with ID %s not found.", jobID)
+	}
+
+	job.SetStatus(status)
+	job.SetErrorMessage(errMsg)
+
+	if jt.store != nil {
+		return jt.store.UpdateProgress(jobRecordFor(job, jt.pendingDeps[jobID]))
+	}
+	return nil
+}
+
 func (jt *JobTracker) CancelJob(jobID string) error {
 	jt.mu.Lock()
 	defer jt.mu.Unlock()
@@ -200,12 +483,37 @@ func (jt *JobTracker) UnlockDependencies(jobID string) []Job {
 	for _, successorID := range successors {
 		jt.pendingDeps[successorID]--
 
+		if jt.store != nil {
+			jt.store.UpdatePendingDeps(successorID, jt.pendingDeps[successorID])
+		}
+
 		if jt.pendingDeps[successorID] == 0 {
 			job := jt.activeJobs[successorID]
 			job.SetStatus(pb.GetJobStatusResponse_JOB_STATUS_QUEUED)
+			if jt.store != nil {
+				jt.store.UpdateStatus(successorID, pb.GetJobStatusResponse_JOB_STATUS_QUEUED)
+			}
 			unlockedJobs = append(unlockedJobs, job)
 		}
 	}
 
 	return unlockedJobs
 }
+
+// SaveProgress writes through a job's current task/frame counters so a
+// crash between task completions doesn't lose more than the single
+// in-flight update.
+func (jt *JobTracker) SaveProgress(jobID string) error {
+	jt.mu.RLock()
+	job, exists := jt.activeJobs[jobID]
+	pendingDeps := jt.pendingDeps[jobID]
+	jt.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("[ERROR] Job with ID %s not found.", jobID)
+	}
+	if jt.store == nil {
+		return nil
+	}
+	return jt.store.UpdateProgress(jobRecordFor(job, pendingDeps))
+}
@@ -0,0 +1,644 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// taskEnvelope is the durable, asynq-style wrapper RedisScheduler stores
+// around the existing proto task payload: just enough routing and retry
+// bookkeeping to rebuild a *Task without a round trip through the
+// coordinator's in-memory maps.
+type taskEnvelope struct {
+	TaskID        string          `json:"task_id"`
+	JobID         string          `json:"job_id"`
+	FrameID       string          `json:"frame_id"`
+	Kind          string          `json:"kind"` // "render", "merge", "composite" - see encodeTaskPayload
+	Payload       json.RawMessage `json:"payload"`
+	Retries       int32           `json:"retries"`
+	Priority      float64         `json:"priority"`
+	EnqueuedAt    time.Time       `json:"enqueued_at"`
+	LeaseDeadline time.Time       `json:"lease_deadline"`
+	Timeout       time.Duration   `json:"timeout"`    // max wall-clock once dispatched; see taskTimeout
+	SessionID     string          `json:"session_id"` // worker session this was last handed to; see AttachSession
+}
+
+// DefaultLeaseDuration is how long RedisScheduler gives a worker to finish a
+// dispatched task before StartLeaseSweeper considers it abandoned and
+// reclaims it.
+const DefaultLeaseDuration = 2 * time.Minute
+
+// RedisScheduler is a durable, crash-safe task queue modeled on asynq: a
+// pending ZSET, an active list, and a leases ZSET per worker type, plus a
+// scheduled ZSET for tasks backing off after a failed lease. A coordinator
+// restart never drops a task - everything that matters lives in Redis, not
+// in this process's heap. The pending ZSET is scored the same way
+// MemoryScheduler's heap is (see ScoreFunc), so SKEWER_QUEUE_BACKEND=redis is
+// a genuine drop-in for MemoryScheduler's priority-scored dispatch
+// (chunk1-1), not just a durable FIFO - StartLeaseSweeper's periodic rescore
+// keeps the age bonus honest the same way StartRescorer does for the heap.
+type RedisScheduler struct {
+	rdb         *redis.Client
+	leaseTime   time.Duration
+	pollBackoff time.Duration
+
+	// selector gates which pending task a worker's profile is allowed to
+	// dequeue. Defaults to DefaultWorkerSelector.
+	selector WorkerSelector
+
+	// ScoreFunc picks how candidates in the pending ZSET are ranked. Defaults
+	// to DefaultScoreFunc with a successorCount of 0 - RedisScheduler has no
+	// DAG wired in, unlike MemoryScheduler.scoreTask.
+	ScoreFunc ScoreFunc
+}
+
+func NewRedisScheduler(rdb *redis.Client, leaseTime time.Duration) *RedisScheduler {
+	return &RedisScheduler{
+		rdb:         rdb,
+		leaseTime:   leaseTime,
+		pollBackoff: 250 * time.Millisecond,
+		selector:    DefaultWorkerSelector{},
+	}
+}
+
+// SetWorkerSelector overrides the default resource/feature matching policy.
+// Safe to call once at startup, before traffic.
+func (r *RedisScheduler) SetWorkerSelector(selector WorkerSelector) {
+	r.selector = selector
+}
+
+// scoreEnvelope scores env via r.ScoreFunc (falling back to DefaultScoreFunc),
+// the same policy MemoryScheduler.scoreTask applies to its heap.
+func (r *RedisScheduler) scoreEnvelope(env *taskEnvelope) float64 {
+	task, err := taskFromEnvelope(env)
+	if err != nil {
+		return 0
+	}
+	scoreFn := r.ScoreFunc
+	if scoreFn == nil {
+		scoreFn = DefaultScoreFunc
+	}
+	return scoreFn(task, 0)
+}
+
+func pendingKey(workerType string) string   { return "skewer-coordinator:" + workerType + ":pending" }
+func activeKey(workerType string) string    { return "skewer-coordinator:" + workerType + ":active" }
+func deadKey(workerType string) string      { return "skewer-coordinator:" + workerType + ":dead" }
+func leasesKey(workerType string) string    { return "skewer-coordinator:" + workerType + ":leases" }
+func scheduledKey(workerType string) string { return "skewer-coordinator:" + workerType + ":scheduled" }
+func taskKey(taskID string) string          { return "skewer-coordinator:task:" + taskID }
+
+func workerTypeForKind(kind string) string {
+	switch kind {
+	case "render":
+		return "skewer"
+	case "merge", "composite":
+		return "loom"
+	default:
+		return "none"
+	}
+}
+
+// retryBackoff is how long a reclaimed task sits in the scheduled ZSET
+// before it's eligible for pending again - exponential, capped at a minute,
+// so a worker pool that's wedged doesn't get hammered with the same
+// handful of tasks every sweep tick.
+func retryBackoff(retries int32) time.Duration {
+	d := 5 * time.Second * time.Duration(int64(1)<<uint(retries))
+	if d > time.Minute {
+		d = time.Minute
+	}
+	return d
+}
+
+// enqueueScript atomically writes the task envelope and adds its ID to the
+// pending ZSET at ARGV[3], so a crash between the two can never happen.
+var enqueueScript = redis.NewScript(`
+	redis.call('SET', KEYS[1], ARGV[1])
+	redis.call('ZADD', KEYS[2], ARGV[3], ARGV[2])
+	return 1
+`)
+
+func (r *RedisScheduler) EnqueueTask(payload interface{}, jobID string, frameID string, priority float64) (string, error) {
+	workerType := workerTypeFor(payload)
+	if workerType == "none" {
+		return "", fmt.Errorf("[Error] Unknown task payload type")
+	}
+
+	kind, data, err := encodeTaskPayload(payload)
+	if err != nil {
+		return "", err
+	}
+
+	taskID := uuid.New().String()
+	env := &taskEnvelope{
+		TaskID:     taskID,
+		JobID:      jobID,
+		FrameID:    frameID,
+		Kind:       kind,
+		Payload:    data,
+		Priority:   priority,
+		EnqueuedAt: time.Now(),
+		Timeout:    taskTimeout(payload),
+	}
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("[REDIS-SCHEDULER] Failed to marshal task %s: %w", taskID, err)
+	}
+
+	ctx := context.Background()
+	score := r.scoreEnvelope(env)
+	if err := enqueueScript.Run(ctx, r.rdb, []string{taskKey(taskID), pendingKey(workerType)}, envData, taskID, score).Err(); err != nil {
+		return "", fmt.Errorf("[REDIS-SCHEDULER] Failed to enqueue task %s: %w", taskID, err)
+	}
+	return taskID, nil
+}
+
+func (r *RedisScheduler) GetNextTask(ctx context.Context, worker *WorkerProfile) (*Task, error) {
+	if worker == nil || len(worker.Capabilities) == 0 {
+		return nil, fmt.Errorf("[ERROR] No capabilities provided")
+	}
+
+	workerType := "none"
+	for _, capability := range worker.Capabilities {
+		if capability == "skewer" || capability == "loom" {
+			workerType = capability
+			break
+		}
+	}
+	if workerType == "none" {
+		return nil, fmt.Errorf("[ERROR] No compatible worker type found")
+	}
+
+	for {
+		task, err := r.dispatchMatching(ctx, workerType, worker)
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			return task, nil
+		}
+
+		// Nothing pending right now matches this worker - poll instead of
+		// blocking forever, so a Redis outage or ctx cancellation is
+		// noticed promptly.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(r.pollBackoff):
+		}
+	}
+}
+
+// dispatchMatching scans workerType's pending ZSET, highest score first, for
+// the first task worker's profile satisfies, and atomically claims exactly
+// that one. This is an O(n) scan rather than an atomic Lua pop - the
+// tradeoff PurgeJobTasks already makes, for the same reason: Redis has no
+// server-side way to filter a ZSET by requirements, only rank it by score.
+// Returns (nil, nil) if nothing pending right now matches.
+func (r *RedisScheduler) dispatchMatching(ctx context.Context, workerType string, worker *WorkerProfile) (*Task, error) {
+	ids, err := r.rdb.ZRevRange(ctx, pendingKey(workerType), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("[REDIS-SCHEDULER] Failed to scan %s pending: %w", workerType, err)
+	}
+
+	for _, taskID := range ids {
+		env, err := r.getEnvelope(ctx, taskID)
+		if err != nil {
+			continue // envelope vanished between ZRevRange and now - skip it
+		}
+		task, err := taskFromEnvelope(env)
+		if err != nil {
+			continue
+		}
+		if !r.selector.Ok(task, worker) {
+			continue
+		}
+
+		// ZRem removes by member, so this only ever claims the exact
+		// taskID we just vetted - if another goroutine already claimed it
+		// first, removed comes back 0 and we move on to the next
+		// candidate instead of double-dispatching.
+		removed, err := r.rdb.ZRem(ctx, pendingKey(workerType), taskID).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+
+		deadline := time.Now().Add(r.leaseTime)
+		r.rdb.RPush(ctx, activeKey(workerType), taskID)
+		r.rdb.ZAdd(ctx, leasesKey(workerType), redis.Z{Score: float64(deadline.Unix()), Member: taskID})
+		task.StartedAt = time.Now()
+		task.LeaseDeadline = deadline
+		task.Deadline = task.StartedAt.Add(task.Timeout)
+		return task, nil
+	}
+
+	return nil, nil
+}
+
+func taskFromEnvelope(env *taskEnvelope) (*Task, error) {
+	payload, err := decodeTaskPayload(&TaskRecord{ID: env.TaskID, Kind: env.Kind, Payload: env.Payload})
+	if err != nil {
+		return nil, err
+	}
+	return &Task{
+		ID:        env.TaskID,
+		JobID:     env.JobID,
+		FrameID:   env.FrameID,
+		Payload:   payload,
+		CreatedAt: env.EnqueuedAt,
+		Retries:   env.Retries,
+		Priority:  env.Priority,
+		Timeout:   env.Timeout,
+		SessionID: env.SessionID,
+	}, nil
+}
+
+func (r *RedisScheduler) getEnvelope(ctx context.Context, taskID string) (*taskEnvelope, error) {
+	data, err := r.rdb.Get(ctx, taskKey(taskID)).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("[REDIS-SCHEDULER] Failed to load task %s: %w", taskID, err)
+	}
+	var env taskEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("[REDIS-SCHEDULER] Failed to unmarshal task %s: %w", taskID, err)
+	}
+	return &env, nil
+}
+
+// RequeueTask moves a task back from active to pending, bumping Retries and
+// placing it at its rescored position. It's a raw "put it back" with no retry-limit or backoff
+// logic of its own - that's what ReportTaskFailure is for. RequeueTask
+// stays around for GetWorkStream's "stream.Send failed" path, where the
+// task never reached the worker at all, same as MemoryScheduler.RequeueTask.
+func (r *RedisScheduler) RequeueTask(taskID string) {
+	ctx := context.Background()
+	env, err := r.getEnvelope(ctx, taskID)
+	if err != nil {
+		return // envelope's already gone - nothing to requeue
+	}
+	workerType := workerTypeForKind(env.Kind)
+
+	env.Retries++
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+
+	r.rdb.Set(ctx, taskKey(taskID), envData, 0)
+	r.rdb.LRem(ctx, activeKey(workerType), 0, taskID)
+	r.rdb.ZRem(ctx, leasesKey(workerType), taskID)
+	r.rdb.ZAdd(ctx, pendingKey(workerType), redis.Z{Score: r.scoreEnvelope(env), Member: taskID})
+}
+
+// MarkTaskComplete removes a task from active tracking and deletes its
+// envelope, mirroring MemoryScheduler.MarkTaskComplete.
+func (r *RedisScheduler) MarkTaskComplete(taskID string) (*Task, bool) {
+	ctx := context.Background()
+	env, err := r.getEnvelope(ctx, taskID)
+	if err != nil {
+		return nil, false
+	}
+	workerType := workerTypeForKind(env.Kind)
+
+	r.rdb.LRem(ctx, activeKey(workerType), 0, taskID)
+	r.rdb.ZRem(ctx, leasesKey(workerType), taskID)
+	r.rdb.Del(ctx, taskKey(taskID))
+
+	payload, err := decodeTaskPayload(&TaskRecord{ID: env.TaskID, Kind: env.Kind, Payload: env.Payload})
+	if err != nil {
+		return nil, false
+	}
+	return &Task{
+		ID:       env.TaskID,
+		JobID:    env.JobID,
+		FrameID:  env.FrameID,
+		Payload:  payload,
+		Retries:  env.Retries,
+		Priority: env.Priority,
+	}, true
+}
+
+// ReportTaskFailure pulls taskID out of active, bumps its retry count, and
+// either schedules a backed-off retry (via the scheduled ZSET, promoted by
+// promoteScheduled) or dead-letters it for good - same rule as
+// MemoryScheduler.ReportTaskFailure. Unlike the old reclaimExpiredLease
+// behavior, a dead-lettered envelope is kept (not deleted), so DeadLetter
+// and RequeueDeadLetter have something to work with.
+func (r *RedisScheduler) ReportTaskFailure(taskID string, taskErr TaskError) (*Task, bool) {
+	ctx := context.Background()
+	env, err := r.getEnvelope(ctx, taskID)
+	if err != nil {
+		return nil, false
+	}
+	workerType := workerTypeForKind(env.Kind)
+
+	r.rdb.LRem(ctx, activeKey(workerType), 0, taskID)
+	r.rdb.ZRem(ctx, leasesKey(workerType), taskID)
+
+	env.Retries++
+	task, err := taskFromEnvelope(env)
+	if err != nil {
+		return nil, false
+	}
+
+	if errors.Is(taskErr.Reason, ErrNonRetryable) || env.Retries > MaxTaskRetries {
+		envData, marshalErr := json.Marshal(env)
+		if marshalErr == nil {
+			r.rdb.Set(ctx, taskKey(taskID), envData, 0)
+		}
+		r.rdb.LPush(ctx, deadKey(workerType), taskID)
+		fmt.Printf("[REDIS-SCHEDULER] Task %s dead-lettered after %d retries (%s): %s\n", taskID, env.Retries, taskErr.Reason, taskErr.Detail)
+		return task, true
+	}
+
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return task, false
+	}
+	r.rdb.Set(ctx, taskKey(taskID), envData, 0)
+	readyAt := time.Now().Add(retryBackoff(env.Retries)).Unix()
+	r.rdb.ZAdd(ctx, scheduledKey(workerType), redis.Z{Score: float64(readyAt), Member: taskID})
+	fmt.Printf("[REDIS-SCHEDULER] Task %s failed (%s), scheduled for retry %d/%d: %s\n", taskID, taskErr.Reason, env.Retries, MaxTaskRetries, taskErr.Detail)
+	return task, false
+}
+
+// DeadLetter scans both worker types' dead lists for envelopes belonging to
+// jobID - the same O(n)-scan tradeoff as PurgeJobTasks, acceptable since
+// operator inspection is rare next to EnqueueTask/GetNextTask traffic.
+func (r *RedisScheduler) DeadLetter(jobID string) []*Task {
+	ctx := context.Background()
+	var tasks []*Task
+	for _, workerType := range []string{"skewer", "loom"} {
+		ids, err := r.rdb.LRange(ctx, deadKey(workerType), 0, -1).Result()
+		if err != nil {
+			continue
+		}
+		for _, taskID := range ids {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil || env.JobID != jobID {
+				continue
+			}
+			if task, err := taskFromEnvelope(env); err == nil {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks
+}
+
+// RequeueDeadLetter finds taskID in either worker type's dead list, resets
+// its retry count, and pushes it back onto pending.
+func (r *RedisScheduler) RequeueDeadLetter(taskID string) error {
+	ctx := context.Background()
+	for _, workerType := range []string{"skewer", "loom"} {
+		removed, err := r.rdb.LRem(ctx, deadKey(workerType), 1, taskID).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+
+		env, err := r.getEnvelope(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("[REDIS-SCHEDULER] Task %s was dead-lettered but its envelope is gone: %w", taskID, err)
+		}
+		env.Retries = 0
+		env.EnqueuedAt = time.Now()
+		envData, err := json.Marshal(env)
+		if err != nil {
+			return fmt.Errorf("[REDIS-SCHEDULER] Failed to marshal requeued dead letter %s: %w", taskID, err)
+		}
+		r.rdb.Set(ctx, taskKey(taskID), envData, 0)
+		r.rdb.ZAdd(ctx, pendingKey(workerType), redis.Z{Score: r.scoreEnvelope(env), Member: taskID})
+		return nil
+	}
+	return fmt.Errorf("[REDIS-SCHEDULER] Task %s not found in any dead-letter list", taskID)
+}
+
+// AttachSession records that sessionID now holds taskID, for later
+// Reconcile comparisons. A no-op if taskID's envelope is already gone.
+func (r *RedisScheduler) AttachSession(taskID, sessionID string) {
+	ctx := context.Background()
+	env, err := r.getEnvelope(ctx, taskID)
+	if err != nil {
+		return
+	}
+	env.SessionID = sessionID
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+	r.rdb.Set(ctx, taskKey(taskID), envData, 0)
+}
+
+// SessionSnapshot scans both worker types' active lists for envelopes
+// attributed to sessionID - the same O(n)-scan tradeoff PurgeJobTasks and
+// DeadLetter already make, acceptable at a periodic reconcile's cadence.
+func (r *RedisScheduler) SessionSnapshot(sessionID string) []*Task {
+	ctx := context.Background()
+	var tasks []*Task
+	for _, workerType := range []string{"skewer", "loom"} {
+		ids, err := r.rdb.LRange(ctx, activeKey(workerType), 0, -1).Result()
+		if err != nil {
+			continue
+		}
+		for _, taskID := range ids {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil || env.SessionID != sessionID {
+				continue
+			}
+			if task, err := taskFromEnvelope(env); err == nil {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks
+}
+
+// AdoptTask re-inserts task into active tracking - a fresh envelope, a place
+// on its worker type's active list, and a lease - for a task a worker's
+// reconcile report claims to hold that this scheduler's Redis state had lost
+// track of entirely. StartedAt is reset to now and Deadline to now plus
+// Timeout. Without a lease
+// entry, reclaimExpiredLeases would never notice if this worker goes dark on
+// the task again, since it only ever scans leasesKey.
+func (r *RedisScheduler) AdoptTask(task *Task) {
+	kind, payload, err := encodeTaskPayload(task.Payload)
+	if err != nil {
+		return
+	}
+	task.StartedAt = time.Now()
+	task.Deadline = task.StartedAt.Add(task.Timeout)
+	task.LeaseDeadline = time.Now().Add(r.leaseTime)
+
+	env := &taskEnvelope{
+		TaskID:        task.ID,
+		JobID:         task.JobID,
+		FrameID:       task.FrameID,
+		Kind:          kind,
+		Payload:       payload,
+		Retries:       task.Retries,
+		Priority:      task.Priority,
+		EnqueuedAt:    task.CreatedAt,
+		LeaseDeadline: task.LeaseDeadline,
+		Timeout:       task.Timeout,
+		SessionID:     task.SessionID,
+	}
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	workerType := workerTypeForKind(kind)
+	r.rdb.Set(ctx, taskKey(task.ID), envData, 0)
+	r.rdb.RPush(ctx, activeKey(workerType), task.ID)
+	r.rdb.ZAdd(ctx, leasesKey(workerType), redis.Z{Score: float64(task.LeaseDeadline.Unix()), Member: task.ID})
+}
+
+// PurgeJobTasks is a best-effort scan of the pending and active lists for
+// both worker types. Redis has no secondary index by JobID, so this is
+// O(queue length) rather than the map-lookup MemoryScheduler gets away
+// with - acceptable since CancelJob/timeout cascades are rare compared to
+// EnqueueTask/GetNextTask traffic.
+func (r *RedisScheduler) PurgeJobTasks(jobID string) error {
+	ctx := context.Background()
+	for _, workerType := range []string{"skewer", "loom"} {
+		ids, err := r.rdb.ZRange(ctx, pendingKey(workerType), 0, -1).Result()
+		if err != nil {
+			return fmt.Errorf("[REDIS-SCHEDULER] Failed to scan %s: %w", pendingKey(workerType), err)
+		}
+		for _, taskID := range ids {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil || env.JobID != jobID {
+				continue
+			}
+			r.rdb.ZRem(ctx, pendingKey(workerType), taskID)
+			r.rdb.ZRem(ctx, leasesKey(workerType), taskID)
+			r.rdb.ZRem(ctx, scheduledKey(workerType), taskID)
+			r.rdb.Del(ctx, taskKey(taskID))
+		}
+
+		ids, err = r.rdb.LRange(ctx, activeKey(workerType), 0, -1).Result()
+		if err != nil {
+			return fmt.Errorf("[REDIS-SCHEDULER] Failed to scan %s: %w", activeKey(workerType), err)
+		}
+		for _, taskID := range ids {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil || env.JobID != jobID {
+				continue
+			}
+			r.rdb.LRem(ctx, activeKey(workerType), 0, taskID)
+			r.rdb.ZRem(ctx, leasesKey(workerType), taskID)
+			r.rdb.ZRem(ctx, scheduledKey(workerType), taskID)
+			r.rdb.Del(ctx, taskKey(taskID))
+		}
+	}
+	return nil
+}
+
+// GetQueueLength returns the combined pending length across worker types,
+// mirroring MemoryScheduler.GetQueueLength for KEDA.
+func (r *RedisScheduler) GetQueueLength() int {
+	ctx := context.Background()
+	var total int64
+	for _, workerType := range []string{"skewer", "loom"} {
+		total += r.rdb.ZCard(ctx, pendingKey(workerType)).Val()
+	}
+	return int(total)
+}
+
+// StartLeaseSweeper is the Redis-side analog of Server.StartSessionReaper:
+// it scans the leases ZSET for deadlines that have passed - almost always a
+// worker that died without reporting a result - and reports each as a
+// retryable failure (a backed-off retry in the scheduled ZSET, or a dead
+// letter), then scans the scheduled ZSET for retries that are ready to run
+// again and promotes them back into pending. RedisScheduler tracks per-task
+// lease deadlines directly rather than through the WorkerRegistry's session
+// heartbeats, since it already durably records each dispatched task's lease
+// deadline in the leases ZSET. It also
+// rescores the pending ZSET each tick, the same job StartRescorer does for
+// MemoryScheduler's heap.
+func (r *RedisScheduler) StartLeaseSweeper(ctx context.Context, checkInterval time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reclaimExpiredLeases(ctx)
+			r.promoteScheduled(ctx)
+			r.rescorePending(ctx)
+		}
+	}
+}
+
+func (r *RedisScheduler) reclaimExpiredLeases(ctx context.Context) {
+	now := fmt.Sprintf("%d", time.Now().Unix())
+	for _, workerType := range []string{"skewer", "loom"} {
+		expired, err := r.rdb.ZRangeByScore(ctx, leasesKey(workerType), &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+		if err != nil {
+			fmt.Printf("[REDIS-SCHEDULER] Failed to scan %s leases: %v\n", workerType, err)
+			continue
+		}
+		for _, taskID := range expired {
+			r.reclaimExpiredLease(taskID)
+		}
+	}
+}
+
+// reclaimExpiredLease treats an expired lease as a retryable failure and
+// routes it through ReportTaskFailure, same as any worker-reported one -
+// it's just as valid a reason to back off and retry (or dead-letter, past
+// MaxTaskRetries) as an explicit failure report.
+func (r *RedisScheduler) reclaimExpiredLease(taskID string) {
+	r.ReportTaskFailure(taskID, NewTaskError(true, "worker lease expired before the task completed"))
+}
+
+func (r *RedisScheduler) promoteScheduled(ctx context.Context) {
+	now := fmt.Sprintf("%d", time.Now().Unix())
+	for _, workerType := range []string{"skewer", "loom"} {
+		ready, err := r.rdb.ZRangeByScore(ctx, scheduledKey(workerType), &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+		if err != nil {
+			fmt.Printf("[REDIS-SCHEDULER] Failed to scan %s scheduled retries: %v\n", workerType, err)
+			continue
+		}
+		for _, taskID := range ready {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil {
+				continue
+			}
+			r.rdb.ZRem(ctx, scheduledKey(workerType), taskID)
+			r.rdb.ZAdd(ctx, pendingKey(workerType), redis.Z{Score: r.scoreEnvelope(env), Member: taskID})
+		}
+	}
+}
+
+// rescorePending recomputes every pending task's score and re-ZAdds it - the
+// ZSET analog of MemoryScheduler's heap.Init(StartRescorer). Scores drift
+// over time (age bonus, retries), which a ZSET doesn't notice on its own;
+// without this, a starved low-priority task would never climb back to the
+// top of dispatchMatching's ZRevRange scan.
+func (r *RedisScheduler) rescorePending(ctx context.Context) {
+	for _, workerType := range []string{"skewer", "loom"} {
+		ids, err := r.rdb.ZRange(ctx, pendingKey(workerType), 0, -1).Result()
+		if err != nil {
+			fmt.Printf("[REDIS-SCHEDULER] Failed to scan %s pending for rescore: %v\n", workerType, err)
+			continue
+		}
+		for _, taskID := range ids {
+			env, err := r.getEnvelope(ctx, taskID)
+			if err != nil {
+				continue
+			}
+			r.rdb.ZAdd(ctx, pendingKey(workerType), redis.Z{Score: r.scoreEnvelope(env), Member: taskID})
+		}
+	}
+}
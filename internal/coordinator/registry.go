@@ -0,0 +1,185 @@
+package coordinator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeartbeatInterval is how often a connected worker is expected to call
+// Heartbeat. MissedHeartbeatLimit is how many consecutive intervals it can
+// go quiet before the session is declared dead - long enough to ride out a
+// slow GC pause or a network blip, short enough that a genuinely dead
+// worker's tasks come back quickly instead of sitting stalled.
+const (
+	HeartbeatInterval    = 20 * time.Second
+	MissedHeartbeatLimit = 3
+)
+
+// SessionInfo is what the WorkerRegistry tracks about one worker
+// connection. A worker that reconnects - process restart, or a network blip
+// past MissedHeartbeatLimit - gets an entirely new SessionID with no memory
+// of what the old one held, so a late heartbeat from an already-reaped
+// session can never resurrect it and double-own its tasks.
+type SessionInfo struct {
+	SessionID     string
+	WorkerID      string
+	Capabilities  []string
+	LastHeartbeat time.Time
+
+	// TaskIDs is the full set of tasks this session last reported holding,
+	// replaced wholesale on every Heartbeat - not accumulated - so a task
+	// the worker silently dropped stops being "owned" on the next
+	// heartbeat instead of waiting for the whole session to time out.
+	TaskIDs map[string]bool
+
+	// Draining, once set, tells GetWorkStream to stop handing this session
+	// new tasks so an operator can cordon a node without killing its
+	// in-flight work.
+	Draining bool
+}
+
+// WorkerRegistry tracks every connected worker by session rather than by
+// self-reported WorkerID, so a reconnecting worker is always treated as a
+// fresh identity instead of silently inheriting a stale task list.
+type WorkerRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*SessionInfo
+}
+
+func NewWorkerRegistry() *WorkerRegistry {
+	return &WorkerRegistry{sessions: make(map[string]*SessionInfo)}
+}
+
+// NewSession mints a session UUID for a worker that just opened
+// GetWorkStream and starts tracking its heartbeats.
+func (r *WorkerRegistry) NewSession(workerID string, capabilities []string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessionID := uuid.New().String()
+	r.sessions[sessionID] = &SessionInfo{
+		SessionID:     sessionID,
+		WorkerID:      workerID,
+		Capabilities:  capabilities,
+		LastHeartbeat: time.Now(),
+		TaskIDs:       make(map[string]bool),
+	}
+	return sessionID
+}
+
+// Heartbeat records that sessionID is alive and currently holds exactly
+// taskIDs. Returns false if sessionID isn't recognized - either it was
+// never issued, or it already missed enough heartbeats to be reaped - so
+// the caller can tell the worker to reconnect via GetWorkStream instead of
+// silently accepting a heartbeat from a session that no longer exists.
+func (r *WorkerRegistry) Heartbeat(sessionID string, taskIDs []string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	s.LastHeartbeat = time.Now()
+	s.TaskIDs = make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		s.TaskIDs[id] = true
+	}
+	return true
+}
+
+// AttachTask records that sessionID has just been handed taskID, ahead of
+// the next heartbeat confirming it.
+func (r *WorkerRegistry) AttachTask(sessionID, taskID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, exists := r.sessions[sessionID]; exists {
+		s.TaskIDs[taskID] = true
+	}
+}
+
+// ReleaseTask drops taskID from whichever session holds it. Called as soon
+// as ReportTaskResult receives the task's terminal result.
+func (r *WorkerRegistry) ReleaseTask(taskID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.sessions {
+		delete(s.TaskIDs, taskID)
+	}
+}
+
+// Drain marks sessionID so GetWorkStream stops handing it new tasks.
+// Reports false if sessionID isn't a live session.
+func (r *WorkerRegistry) Drain(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	s.Draining = true
+	return true
+}
+
+// IsDraining reports whether sessionID has been told to stop accepting new
+// tasks.
+func (r *WorkerRegistry) IsDraining(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.sessions[sessionID]
+	return exists && s.Draining
+}
+
+// DeadSessions evicts and returns every session whose last heartbeat is
+// older than MissedHeartbeatLimit intervals, each mapped to every TaskID it
+// was last known to hold - the whole batch comes back at once instead of
+// trickling in behind separate per-task timers.
+func (r *WorkerRegistry) DeadSessions(now time.Time) map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deadline := HeartbeatInterval * MissedHeartbeatLimit
+	dead := make(map[string][]string)
+	for sessionID, s := range r.sessions {
+		if now.Sub(s.LastHeartbeat) <= deadline {
+			continue
+		}
+		taskIDs := make([]string, 0, len(s.TaskIDs))
+		for taskID := range s.TaskIDs {
+			taskIDs = append(taskIDs, taskID)
+		}
+		dead[sessionID] = taskIDs
+		delete(r.sessions, sessionID)
+	}
+	return dead
+}
+
+// Snapshot returns a defensive copy of every live session, for the admin
+// ListWorkers RPC.
+func (r *WorkerRegistry) Snapshot() []*SessionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]*SessionInfo, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		taskIDs := make(map[string]bool, len(s.TaskIDs))
+		for id := range s.TaskIDs {
+			taskIDs[id] = true
+		}
+		out = append(out, &SessionInfo{
+			SessionID:     s.SessionID,
+			WorkerID:      s.WorkerID,
+			Capabilities:  append([]string{}, s.Capabilities...),
+			LastHeartbeat: s.LastHeartbeat,
+			TaskIDs:       taskIDs,
+			Draining:      s.Draining,
+		})
+	}
+	return out
+}
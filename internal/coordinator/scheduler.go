@@ -1,8 +1,11 @@
 package coordinator
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -10,34 +13,381 @@ import (
 	pb "github.com/skewer-project/skewer/api/proto/coordinator/v1"
 )
 
+// Scheduler is the task-queue backend: everywhere else in the coordinator
+// talks to whichever one is configured through this interface, never
+// through a concrete type. MemoryScheduler is the in-process default;
+// RedisScheduler (redis_scheduler.go) durably persists the same operations
+// so a coordinator restart doesn't drop an in-flight render. Backends that
+// support pausing a job additionally implement Pausable - check with a type
+// assertion, since not every backend has to.
+type Scheduler interface {
+	EnqueueTask(payload interface{}, jobID string, frameID string, priority float64) (string, error)
+	GetNextTask(ctx context.Context, worker *WorkerProfile) (*Task, error)
+	RequeueTask(taskID string)
+	MarkTaskComplete(taskID string) (*Task, bool)
+	PurgeJobTasks(jobID string) error
+	GetQueueLength() int
+
+	// ReportTaskFailure classifies why taskID failed and either re-enqueues
+	// it with exponential backoff (retryable, under MaxTaskRetries) or moves
+	// it to its job's dead-letter list for good (non-retryable, or retryable
+	// but out of retries). The returned bool is true in the dead-letter
+	// case; the returned *Task is nil only if taskID wasn't being tracked as
+	// active at all.
+	ReportTaskFailure(taskID string, taskErr TaskError) (*Task, bool)
+
+	// DeadLetter returns jobID's dead-lettered tasks, for operator
+	// inspection.
+	DeadLetter(jobID string) []*Task
+
+	// RequeueDeadLetter moves taskID out of the dead-letter list and back
+	// onto its candidate queue with Retries reset to 0, for an operator who
+	// fixed whatever made it non-retryable.
+	RequeueDeadLetter(taskID string) error
+
+	// AttachSession records that sessionID now holds taskID, for later
+	// Reconcile comparisons (see Server.handleReconcileResponse). Called
+	// by GetWorkStream just before the task is sent to the worker. A
+	// no-op if taskID isn't tracked as active.
+	AttachSession(taskID, sessionID string)
+
+	// SessionSnapshot returns a copy of every task this scheduler currently
+	// attributes to sessionID (see AttachSession) - what a reconcile report
+	// from that worker gets compared against.
+	SessionSnapshot(sessionID string) []*Task
+
+	// AdoptTask re-inserts task into active tracking, with StartedAt reset
+	// to now and Deadline to now plus Timeout, for a task a worker's reconcile report claims
+	// to hold that this scheduler had otherwise lost track of entirely.
+	AdoptTask(task *Task)
+}
+
+// Pausable is implemented by Scheduler backends that can withhold a job's
+// queued tasks from dispatch without losing them. MemoryScheduler
+// implements it today; RedisScheduler doesn't yet, so PauseJob/ResumeJob
+// fail loudly for it instead of silently no-op'ing.
+type Pausable interface {
+	PauseJob(jobID string)
+	ResumeJob(jobID string)
+}
+
+// Tunables for DefaultScoreFunc. Exported as vars (not consts) so operators
+// can tune them without a rebuild-from-source-only workflow.
+var (
+	AgeBonusPerSecond     = 0.05 // linear bonus per second a task has waited
+	DependencyBonusWeight = 2.0  // bonus per transitive DAG successor unblocked
+	RetryPenaltyFactor    = 0.75 // score *= RetryPenaltyFactor^Retries
+)
+
+// Priority tiers for Task.Priority / pb.RenderTask.Priority /
+// pb.CompositeTask.Priority, borrowed from Skia's task_scheduler: forced or
+// user-interactive work sits far above everything else, a "try"/preview
+// render outranks normal batch work, and batch work is the baseline every
+// other bonus is computed relative to.
+const (
+	PriorityForced = 100.0
+	PriorityTry    = 10.0
+	PriorityNormal = 1.0
+)
+
+// MaxTaskRetries is how many times ReportTaskFailure will back off and
+// retry a retryable failure before giving up and dead-lettering the task.
+const MaxTaskRetries = 3
+
+// DefaultTaskTimeout bounds how long a dispatched task is allowed to run
+// before Server's per-task deadline watcher reports it as a retryable
+// timeout, for payloads that don't declare their own TimeoutSeconds.
+const DefaultTaskTimeout = 30 * time.Minute
+
+// taskTimeout reads a payload's declared TimeoutSeconds (pb.RenderTask /
+// pb.CompositeTask), falling back to DefaultTaskTimeout when it's unset.
+func taskTimeout(payload any) time.Duration {
+	var seconds int32
+	switch t := payload.(type) {
+	case *pb.RenderTask:
+		seconds = t.TimeoutSeconds
+	case *pb.CompositeTask:
+		seconds = t.TimeoutSeconds
+	}
+	if seconds <= 0 {
+		return DefaultTaskTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ErrRetryable and ErrNonRetryable classify why a task failed. A TaskError
+// always wraps one of the two (see NewTaskError), so callers can still
+// errors.Is against them after unwrapping.
+var (
+	// ErrRetryable marks a transient failure - a network blip, a worker OOM
+	// kill, a Blender crash that doesn't reproduce - worth retrying with
+	// backoff.
+	ErrRetryable = errors.New("retryable task failure")
+	// ErrNonRetryable marks a failure retrying can't fix - a bad .blend, a
+	// missing asset, an engine the scene needs that no worker will ever
+	// report - that should go straight to the dead-letter list instead of
+	// burning retries on it.
+	ErrNonRetryable = errors.New("non-retryable task failure")
+)
+
+// TaskError is what a worker reports through ReportTaskFailure: Reason is
+// always ErrRetryable or ErrNonRetryable, and Detail is the worker's
+// human-readable explanation.
+type TaskError struct {
+	Reason error
+	Detail string
+}
+
+func (e TaskError) Error() string { return fmt.Sprintf("%s: %s", e.Reason, e.Detail) }
+func (e TaskError) Unwrap() error { return e.Reason }
+
+// NewTaskError builds a TaskError from a worker's reported outcome.
+func NewTaskError(retryable bool, detail string) TaskError {
+	if retryable {
+		return TaskError{Reason: ErrRetryable, Detail: detail}
+	}
+	return TaskError{Reason: ErrNonRetryable, Detail: detail}
+}
+
 type Task struct {
 	ID      string
 	JobID   string
 	FrameID string
 	Payload any // *pb.RenderTask or *pb.CompositeTask
 
-	CreatedAt time.Time
-	StartedAt time.Time // WHEN the worker pulled it
-	Retries   int32     // How many times it has been requeued
+	CreatedAt     time.Time
+	StartedAt     time.Time     // WHEN the worker pulled it
+	LeaseDeadline time.Time     // WHEN a backend will consider this task abandoned
+	Timeout       time.Duration // max wall-clock once dispatched; see taskTimeout
+	Deadline      time.Time     // StartedAt + Timeout, set by GetNextTask at dispatch
+	Retries       int32         // How many times it has been requeued
+	Priority      float64       // e.g. PriorityForced/PriorityTry/PriorityNormal
+	SessionID     string        // which worker session this was last handed to; see AttachSession
+}
+
+// Requirements returns the declared TaskRequirements carried by t's payload
+// (pb.RenderTask.Requirements / pb.CompositeTask.Requirements), or nil if
+// the payload type doesn't carry any - callers treat nil as "any worker in
+// the right queue can run it".
+func (t *Task) Requirements() *pb.TaskRequirements {
+	switch p := t.Payload.(type) {
+	case *pb.RenderTask:
+		return p.Requirements
+	case *pb.CompositeTask:
+		return p.Requirements
+	default:
+		return nil
+	}
+}
+
+// ScoreFunc computes a task's dispatch priority; the candidate heap for its
+// worker type keeps the highest score on top. successorCount is how many
+// transitive DAG successors the task's job has queued up behind it (see
+// DAG.GetTransitiveSuccessors). Operators can swap Scheduler.ScoreFunc to
+// plug in a different policy without forking the scheduler.
+type ScoreFunc func(task *Task, successorCount int) float64
+
+// DefaultScoreFunc combines the task's priority tier, how long it's been
+// waiting, how much downstream DAG work it would unblock, and a penalty for
+// tasks that have already failed once - so a fresh task outranks a retry of
+// the same class and age, and starved low-priority work still climbs the
+// queue the longer it waits. A retry that has waited long enough can still
+// outrank a fresh task, since the retry penalty scales the age bonus too.
+func DefaultScoreFunc(t *Task, successorCount int) float64 {
+	age := time.Since(t.CreatedAt).Seconds()
+	base := t.Priority + age*AgeBonusPerSecond + float64(successorCount)*DependencyBonusWeight
+	return base * math.Pow(RetryPenaltyFactor, float64(t.Retries))
+}
+
+// taskHeap is a container/heap-backed max-heap of candidate tasks for one
+// worker type, ordered by score (highest first). Scores move over time (age
+// bonus, retries, DAG successors unlocking), so a heap's usual "compare
+// once, sift never again" assumption doesn't quite hold here - StartRescorer
+// periodically calls heap.Init to restore the invariant instead.
+type taskHeap struct {
+	tasks []*Task
+	score func(*Task) float64
+}
+
+func (h *taskHeap) Len() int           { return len(h.tasks) }
+func (h *taskHeap) Less(i, j int) bool { return h.score(h.tasks[i]) > h.score(h.tasks[j]) }
+func (h *taskHeap) Swap(i, j int)      { h.tasks[i], h.tasks[j] = h.tasks[j], h.tasks[i] }
+func (h *taskHeap) Push(x any)         { h.tasks = append(h.tasks, x.(*Task)) }
+func (h *taskHeap) Pop() any {
+	old := h.tasks
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	h.tasks = old[:n-1]
+	return task
 }
 
-type Scheduler struct {
-	mu          sync.Mutex
-	skewerQueue chan *Task       // Only for Render Tasks (thread safe)
-	loomQueue   chan *Task       // Only for Merge/Composite Tasks (thread safe)
+type MemoryScheduler struct {
+	mu sync.Mutex
+	// cond wakes GetNextTask callers blocked waiting for work, whenever the
+	// queues change or a periodic re-scoring pass runs.
+	cond *sync.Cond
+
+	skewerQueue  *taskHeap // Candidate Render tasks, picked by score, not FIFO
+	loomQueue    *taskHeap // Candidate Merge/Composite tasks, picked by score
+	maxQueueSize int
+
+	// ScoreFunc picks how candidate tasks are ranked. Defaults to
+	// DefaultScoreFunc; set before traffic starts to plug a different
+	// policy.
+	ScoreFunc ScoreFunc
+
 	activeTasks map[string]*Task // Tasks currently being worked on
+
+	// jobGraph, if set, lets scoreTask account for how many queued
+	// successors a task's completion would unblock. Nil is fine - that
+	// bonus is just 0.
+	jobGraph *DAG
+
+	// pausedJobs holds JobIDs that must not have tasks dispatched right now.
+	// heldBack holds the tasks GetNextTask popped for a paused job but
+	// couldn't hand out, so ResumeJob can push them back onto their queue.
+	pausedJobs map[string]bool
+	heldBack   map[string][]*Task
+
+	// selector gates and ranks which connected worker a candidate task may
+	// be dispatched to. Defaults to DefaultWorkerSelector.
+	selector WorkerSelector
+
+	// deadLetters holds tasks ReportTaskFailure has given up on, keyed by
+	// JobID, for DeadLetter/RequeueDeadLetter to inspect or recover.
+	deadLetters map[string][]*Task
 }
 
-func NewScheduler(maxQueueSize int) *Scheduler {
-	return &Scheduler{
-		skewerQueue: make(chan *Task, maxQueueSize),
-		loomQueue:   make(chan *Task, maxQueueSize),
-		activeTasks: make(map[string]*Task),
+// SchedulerOption configures a MemoryScheduler at construction time.
+type SchedulerOption func(*MemoryScheduler)
+
+// WithWorkerSelector overrides the default resource/feature matching policy.
+func WithWorkerSelector(selector WorkerSelector) SchedulerOption {
+	return func(s *MemoryScheduler) {
+		s.selector = selector
 	}
 }
 
-// EnqueueTask adds a new task to the queue
-func (s *Scheduler) EnqueueTask(payload interface{}, jobID string, frameID string) (string, error) {
+func NewMemoryScheduler(maxQueueSize int, opts ...SchedulerOption) *MemoryScheduler {
+	s := &MemoryScheduler{
+		maxQueueSize: maxQueueSize,
+		activeTasks:  make(map[string]*Task),
+		pausedJobs:   make(map[string]bool),
+		heldBack:     make(map[string][]*Task),
+		selector:     DefaultWorkerSelector{},
+		deadLetters:  make(map[string][]*Task),
+	}
+	s.skewerQueue = &taskHeap{score: s.scoreTask}
+	s.loomQueue = &taskHeap{score: s.scoreTask}
+	s.cond = sync.NewCond(&s.mu)
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// scoreTask scores t via s.ScoreFunc (falling back to DefaultScoreFunc),
+// accounting for how many transitive DAG successors t's job has queued up
+// behind it. Must be called with s.mu held - it's only ever invoked from
+// inside heap operations on a queue already locked by the caller.
+func (s *MemoryScheduler) scoreTask(t *Task) float64 {
+	successorCount := 0
+	if s.jobGraph != nil {
+		successorCount = len(s.jobGraph.GetTransitiveSuccessors(t.JobID))
+	}
+	scoreFn := s.ScoreFunc
+	if scoreFn == nil {
+		scoreFn = DefaultScoreFunc
+	}
+	return scoreFn(t, successorCount)
+}
+
+// SetJobGraph wires in the JobTracker's DAG so scoreTask can compute the
+// dependency-unblocking bonus. Safe to call once at startup, before traffic.
+func (s *MemoryScheduler) SetJobGraph(graph *DAG) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobGraph = graph
+}
+
+// PauseJob stops GetNextTask from handing out tasks for jobID. Tasks already
+// in a worker's hands are unaffected - they run to completion.
+func (s *MemoryScheduler) PauseJob(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pausedJobs[jobID] = true
+}
+
+// ResumeJob lets jobID's tasks flow again and re-enqueues anything
+// GetNextTask held back for it while it was paused.
+func (s *MemoryScheduler) ResumeJob(jobID string) {
+	s.mu.Lock()
+	held := s.heldBack[jobID]
+	delete(s.heldBack, jobID)
+	delete(s.pausedJobs, jobID)
+	s.mu.Unlock()
+
+	for _, task := range held {
+		s.pushToQueue(task)
+	}
+}
+
+// queueFor returns the candidate heap for a payload/worker type. Callers
+// must hold s.mu before pushing or popping it.
+func (s *MemoryScheduler) queueFor(workerType string) *taskHeap {
+	switch workerType {
+	case "skewer":
+		return s.skewerQueue
+	case "loom":
+		return s.loomQueue
+	default:
+		return nil
+	}
+}
+
+func workerTypeFor(payload any) string {
+	switch payload.(type) {
+	case *pb.RenderTask:
+		return "skewer"
+	case *pb.MergeTask, *pb.CompositeTask:
+		return "loom"
+	default:
+		return "none"
+	}
+}
+
+// pushToQueue routes a task back onto its correct queue by payload type and
+// wakes anyone blocked in GetNextTask. Shared by RequeueTask and ResumeJob.
+func (s *MemoryScheduler) pushToQueue(task *Task) {
+	workerType := workerTypeFor(task.Payload)
+	queue := s.queueFor(workerType)
+	if queue == nil {
+		fmt.Printf("[SCHEDULER] Unknown task payload type for task %s. Dropping it.\n", task.ID)
+		return
+	}
+
+	s.mu.Lock()
+	if queue.Len() >= s.maxQueueSize {
+		s.mu.Unlock()
+		fmt.Printf("[SCHEDULER] %s queue is full. Lost task %s\n", workerType, task.ID)
+		return
+	}
+	heap.Push(queue, task)
+	s.mu.Unlock()
+	s.cond.Broadcast()
+
+	fmt.Printf("[SCHEDULER] Requeued %s task %s\n", workerType, task.ID)
+}
+
+// EnqueueTask adds a new task to the priority-scored candidate queue.
+func (s *MemoryScheduler) EnqueueTask(payload interface{}, jobID string, frameID string, priority float64) (string, error) {
+	workerType := workerTypeFor(payload)
+	if workerType == "none" {
+		return "", fmt.Errorf("[Error] Unknown task payload type")
+	}
+
 	taskID := uuid.New().String()
 	task := &Task{
 		ID:        taskID,
@@ -45,39 +395,33 @@ func (s *Scheduler) EnqueueTask(payload interface{}, jobID string, frameID strin
 		FrameID:   frameID,
 		Payload:   payload,
 		CreatedAt: time.Now(),
+		Priority:  priority,
+		Timeout:   taskTimeout(payload),
 	}
 
-	// Figure out which queue to put it in, and do it atomically
-	switch payload.(type) {
-	case *pb.RenderTask:
-		select {
-		case s.skewerQueue <- task:
-			return taskID, nil
-		default:
-			return "", fmt.Errorf("[Error] Skewer queue is at capacity")
-		}
-	case *pb.MergeTask, *pb.CompositeTask:
-		select {
-		case s.loomQueue <- task:
-			return taskID, nil
-		default:
-			return "", fmt.Errorf("[Error] Loom queue is at capacity")
-		}
-	default:
-		return "", fmt.Errorf("[Error] Unknown task payload type")
+	queue := s.queueFor(workerType)
+
+	s.mu.Lock()
+	if queue.Len() >= s.maxQueueSize {
+		s.mu.Unlock()
+		return "", fmt.Errorf("[Error] %s queue is at capacity", workerType)
 	}
+	heap.Push(queue, task)
+	s.mu.Unlock()
+	s.cond.Broadcast()
 
+	return taskID, nil
 }
 
 // gRPC streaming handlers will just call this in a loop.
-func (s *Scheduler) GetNextTask(ctx context.Context, capabilities []string) (*Task, error) {
+func (s *MemoryScheduler) GetNextTask(ctx context.Context, worker *WorkerProfile) (*Task, error) {
 
-	if len(capabilities) == 0 {
+	if worker == nil || len(worker.Capabilities) == 0 {
 		return nil, fmt.Errorf("[ERROR] No capabilities provided")
 	}
 
 	workerType := "none"
-	for _, capability := range capabilities {
+	for _, capability := range worker.Capabilities {
 		if capability == "skewer" || capability == "loom" {
 			workerType = capability
 			break
@@ -88,44 +432,106 @@ func (s *Scheduler) GetNextTask(ctx context.Context, capabilities []string) (*Ta
 		return nil, fmt.Errorf("[ERROR] No compatible worker type found")
 	}
 
-	// Get next type based on workerType
+	queue := s.queueFor(workerType)
+
+	// cond.Wait doesn't understand contexts, so spawn a one-shot watcher
+	// that wakes this waiter (and everyone else) the moment ctx is done.
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.cond.Broadcast()
+		case <-stopWatch:
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for {
-		switch workerType {
-		case "skewer":
-			select {
-			case task := <-s.skewerQueue: // Pull ONLY from Skewer Queue
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-				s.mu.Lock()
-				task.StartedAt = time.Now()
-				s.activeTasks[task.ID] = task
-				s.mu.Unlock()
+		task, ok := s.popMatching(queue, worker)
+		if !ok {
+			// Either nothing's queued, or nothing queued right now passes
+			// this worker's profile (e.g. everything pending needs 24GB
+			// VRAM and this node only has 4) - park and wait rather than
+			// dropping anything. A differently-shaped task, or a
+			// differently-shaped worker, may show up next.
+			s.cond.Wait()
+			continue
+		}
 
-				return task, nil
+		if s.pausedJobs[task.JobID] {
+			// Job is paused: hold the task instead of handing it out, and
+			// go around again without waiting - there may be other work.
+			s.heldBack[task.JobID] = append(s.heldBack[task.JobID], task)
+			continue
+		}
 
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
+		task.StartedAt = time.Now()
+		task.Deadline = task.StartedAt.Add(task.Timeout)
+		s.activeTasks[task.ID] = task
+		return task, nil
+	}
+}
+
+// popMatching pops the highest-scoring task in queue that worker's profile
+// satisfies per s.selector.Ok, leaving every task it skipped over back in
+// the queue in its original relative order. Must be called with s.mu held.
+//
+// Cmp isn't consulted here - GetNextTask only ever sees the one worker
+// asking, never a pool of idle candidates to rank against each other, so
+// there's no arbitration point in this pull-based design for it to plug
+// into today.
+func (s *MemoryScheduler) popMatching(queue *taskHeap, worker *WorkerProfile) (*Task, bool) {
+	var skipped []*Task
+	var found *Task
+
+	for queue.Len() > 0 {
+		t := heap.Pop(queue).(*Task)
+		if s.selector.Ok(t, worker) {
+			found = t
+			break
+		}
+		skipped = append(skipped, t)
+	}
 
-		case "loom":
-			select {
-			case task := <-s.loomQueue: // Pull ONLY from Loom Queue
+	for _, t := range skipped {
+		heap.Push(queue, t)
+	}
 
-				s.mu.Lock()
-				task.StartedAt = time.Now()
-				s.activeTasks[task.ID] = task
-				s.mu.Unlock()
+	return found, found != nil
+}
 
-				return task, nil
+// StartRescorer periodically re-heapifies both candidate queues and wakes
+// any callers blocked in GetNextTask. Scores drift over time (age bonus,
+// retries, DAG successors unlocking), which a heap doesn't notice on its
+// own - without this, a starved low-priority task sitting behind a paused
+// job would never climb back to the top.
+func (s *MemoryScheduler) StartRescorer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			heap.Init(s.skewerQueue)
+			heap.Init(s.loomQueue)
+			s.mu.Unlock()
+			s.cond.Broadcast()
 		}
 	}
 }
 
 // Safely removes and returns the task
-func (s *Scheduler) popActiveTask(taskID string) (*Task, bool) {
+func (s *MemoryScheduler) popActiveTask(taskID string) (*Task, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -136,115 +542,127 @@ func (s *Scheduler) popActiveTask(taskID string) (*Task, bool) {
 	return task, exists
 }
 
-func (s *Scheduler) RequeueTask(taskID string) {
+func (s *MemoryScheduler) RequeueTask(taskID string) {
 	task, exists := s.popActiveTask(taskID)
 
 	if exists {
 		task.CreatedAt = time.Now()
 
 		// Push it back onto the correct queue in a goroutine
-		go func(t *Task) {
-			switch t.Payload.(type) {
-
-			// Route to Skewer Queue
-			case *pb.RenderTask:
-				select {
-				case s.skewerQueue <- t:
-					fmt.Printf("[SCHEDULER] Requeued Skewer task %s\n", t.ID)
-				default:
-					fmt.Printf("[SCHEDULER] Skewer queue is full. Lost task %s\n", t.ID)
-				}
-
-			// Route to Loom Queue
-			case *pb.MergeTask, *pb.CompositeTask:
-				select {
-				case s.loomQueue <- t:
-					fmt.Printf("[SCHEDULER] Requeued Loom task %s\n", t.ID)
-				default:
-					fmt.Printf("[SCHEDULER] Loom queue is full. Lost task %s\n", t.ID)
-				}
-			}
-		}(task)
+		go s.pushToQueue(task)
 	}
 }
 
 // MarkTaskComplete removes it from active tracking without doing anything with the values
-func (s *Scheduler) MarkTaskComplete(taskID string) (*Task, bool) {
+func (s *MemoryScheduler) MarkTaskComplete(taskID string) (*Task, bool) {
 	return s.popActiveTask(taskID)
 }
 
-// GetQueueLength returns current queue size for KEDA. NO LOCKS NEEDED.
-func (s *Scheduler) GetQueueLength() int {
-	return len(s.skewerQueue) + len(s.loomQueue)
-}
-
-// StartSweeper runs a background loop to reclaim tasks from dead workers (that may have segfaulted).
-// Call this once right after creating the Scheduler: `go scheduler.StartSweeper(ctx, ...)`
-func (s *Scheduler) StartSweeper(ctx context.Context, timeout time.Duration, checkInterval time.Duration) {
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
+// ReportTaskFailure pops taskID out of active tracking and either schedules
+// a backed-off retry or moves it to its job's dead-letter list for good -
+// see the Scheduler interface doc for the exact rule. retryBackoff is
+// shared with RedisScheduler (defined in redis_scheduler.go).
+func (s *MemoryScheduler) ReportTaskFailure(taskID string, taskErr TaskError) (*Task, bool) {
+	task, exists := s.popActiveTask(taskID)
+	if !exists {
+		return nil, false
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return // Server is shutting down, stop sweeping
-		case <-ticker.C:
-			s.sweep(timeout)
-		}
+	task.Retries++
+	if errors.Is(taskErr.Reason, ErrNonRetryable) || task.Retries > MaxTaskRetries {
+		s.mu.Lock()
+		s.deadLetters[task.JobID] = append(s.deadLetters[task.JobID], task)
+		s.mu.Unlock()
+		fmt.Printf("[SCHEDULER] Task %s dead-lettered after %d retries (%s): %s\n", taskID, task.Retries, taskErr.Reason, taskErr.Detail)
+		return task, true
 	}
-}
 
-func (s *Scheduler) sweep(timeout time.Duration) {
-	now := time.Now()
+	backoff := retryBackoff(task.Retries)
+	fmt.Printf("[SCHEDULER] Task %s failed (%s), retrying in %s: %s\n", taskID, taskErr.Reason, backoff, taskErr.Detail)
+	time.AfterFunc(backoff, func() { s.pushToQueue(task) })
+	return task, false
+}
 
-	// Create a temporary list to hold tasks we need to recover
-	var deadTasks []*Task
+// DeadLetter returns jobID's dead-lettered tasks.
+func (s *MemoryScheduler) DeadLetter(jobID string) []*Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Task(nil), s.deadLetters[jobID]...)
+}
 
-	// Lock just long enough to scan the map and remove the bad entries
+// RequeueDeadLetter finds taskID across every job's dead-letter list,
+// resets its retry count, and pushes it back onto its candidate queue.
+func (s *MemoryScheduler) RequeueDeadLetter(taskID string) error {
 	s.mu.Lock()
-	for id, task := range s.activeTasks {
-		// TODO: Change StartedAt to LastHeartbeat if we implement
-		if now.Sub(task.StartedAt) > timeout {
-			deadTasks = append(deadTasks, task)
-			delete(s.activeTasks, id) // Remove it from active tracking
+	var task *Task
+	for jobID, tasks := range s.deadLetters {
+		for i, t := range tasks {
+			if t.ID == taskID {
+				task = t
+				s.deadLetters[jobID] = append(tasks[:i], tasks[i+1:]...)
+				break
+			}
+		}
+		if task != nil {
+			break
 		}
 	}
 	s.mu.Unlock()
 
-	// Now we are outside the lock. The rest of the server can keep running.
-	// We can safely process the requeues without freezing the scheduler.
-	for _, task := range deadTasks {
-		task.Retries++
-
-		if task.Retries > 3 {
-			fmt.Printf("[SCHEDULER] Task %s failed %d times. Dropping it permanently.\n", task.ID, task.Retries)
-			continue
-		}
+	if task == nil {
+		return fmt.Errorf("[SCHEDULER] Task %s not found in any dead-letter list", taskID)
+	}
 
-		// Push it back to the queue
-		// Figure out which queue the dead task belongs to!
-		switch task.Payload.(type) {
+	task.Retries = 0
+	task.CreatedAt = time.Now()
+	s.pushToQueue(task)
+	return nil
+}
 
-		case *pb.RenderTask:
-			select {
-			case s.skewerQueue <- task:
-				fmt.Printf("[SCHEDULER] Worker timeout! Requeued Skewer task %s (Retry %d/3)\n", task.ID, task.Retries)
-			default:
-				fmt.Printf("[SCHEDULER] Skewer queue is full! Lost timed-out task %s\n", task.ID)
-			}
+// AttachSession records that sessionID now holds taskID, for later
+// Reconcile comparisons. A no-op if taskID isn't currently active.
+func (s *MemoryScheduler) AttachSession(taskID, sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if task, ok := s.activeTasks[taskID]; ok {
+		task.SessionID = sessionID
+	}
+}
 
-		case *pb.MergeTask, *pb.CompositeTask:
-			select {
-			case s.loomQueue <- task:
-				fmt.Printf("[SCHEDULER] Worker timeout! Requeued Loom task %s (Retry %d/3)\n", task.ID, task.Retries)
-			default:
-				fmt.Printf("[SCHEDULER] Loom queue is full! Lost timed-out task %s\n", task.ID)
-			}
+// SessionSnapshot returns a copy of every task currently attributed to
+// sessionID.
+func (s *MemoryScheduler) SessionSnapshot(sessionID string) []*Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tasks []*Task
+	for _, task := range s.activeTasks {
+		if task.SessionID == sessionID {
+			cp := *task
+			tasks = append(tasks, &cp)
 		}
 	}
+	return tasks
 }
 
-func (s *Scheduler) PurgeJobTasks(jobID string) error {
+// AdoptTask re-inserts task into active tracking with a fresh
+// StartedAt/Deadline, for a task a worker's reconcile report claims to hold
+// that this scheduler had lost track of.
+func (s *MemoryScheduler) AdoptTask(task *Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task.StartedAt = time.Now()
+	task.Deadline = task.StartedAt.Add(task.Timeout)
+	s.activeTasks[task.ID] = task
+}
+
+// GetQueueLength returns current queue size for KEDA.
+func (s *MemoryScheduler) GetQueueLength() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skewerQueue.Len() + s.loomQueue.Len()
+}
+
+func (s *MemoryScheduler) PurgeJobTasks(jobID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -253,5 +671,26 @@ func (s *Scheduler) PurgeJobTasks(jobID string) error {
 			delete(s.activeTasks, taskID)
 		}
 	}
+
+	// A job timing out before any worker pulled its first task means every
+	// one of its tasks is still sitting in the pending heap, not
+	// activeTasks - purge those too, or they're still dispatchable long
+	// after the job was marked failed/timed out.
+	purgeQueue(s.skewerQueue, jobID)
+	purgeQueue(s.loomQueue, jobID)
 	return nil
 }
+
+// purgeQueue filters jobID's tasks out of queue's backing slice in place
+// and re-establishes the heap invariant, since removing elements directly
+// (rather than through heap.Pop) can leave it broken.
+func purgeQueue(queue *taskHeap, jobID string) {
+	kept := queue.tasks[:0]
+	for _, task := range queue.tasks {
+		if task.JobID != jobID {
+			kept = append(kept, task)
+		}
+	}
+	queue.tasks = kept
+	heap.Init(queue)
+}
@@ -0,0 +1,87 @@
+package coordinator
+
+// WorkerProfile is what a worker declares about itself when it opens
+// GetWorkStream: how much of each resource it actually has, not just which
+// queue it can pull from. Modeled on Lotus's sector-storage Worker info -
+// enough for a Selector to tell a 4GB node apart from a 24GB one before
+// handing out a task that would just OOM it.
+type WorkerProfile struct {
+	WorkerID     string
+	Capabilities []string // "skewer", "loom" - which queues this worker can pull from
+
+	VRAMMB   int32
+	CPUCores int32
+	Engines  map[string]bool // "cycles", "eevee", "workbench"
+	OS       string
+	Arch     string
+	OIDN     bool
+}
+
+// NewWorkerProfile builds a WorkerProfile from the flat fields a worker
+// reports on GetWorkStreamRequest.
+func NewWorkerProfile(workerID string, capabilities []string, vramMB, cpuCores int32, engines []string, os, arch string, oidn bool) *WorkerProfile {
+	engineSet := make(map[string]bool, len(engines))
+	for _, e := range engines {
+		engineSet[e] = true
+	}
+	return &WorkerProfile{
+		WorkerID:     workerID,
+		Capabilities: capabilities,
+		VRAMMB:       vramMB,
+		CPUCores:     cpuCores,
+		Engines:      engineSet,
+		OS:           os,
+		Arch:         arch,
+		OIDN:         oidn,
+	}
+}
+
+// WorkerSelector decides which connected worker is allowed to take a task,
+// and which of several eligible workers should get it. Ok gates
+// correctness (a worker without enough VRAM must never be offered the
+// task); Cmp ranks eligible workers against each other once Ok has already
+// filtered out the ones that can't run it at all.
+type WorkerSelector interface {
+	// Ok reports whether w has enough declared resources/features to run
+	// task at all.
+	Ok(task *Task, w *WorkerProfile) bool
+
+	// Cmp reports whether worker a should be preferred over worker b for
+	// task, given both already passed Ok. Only meaningful when multiple
+	// idle workers are being compared at once - see the note on
+	// MemoryScheduler.popMatching.
+	Cmp(task *Task, a, b *WorkerProfile) bool
+}
+
+// DefaultWorkerSelector checks a task's declared TaskRequirements (minimum
+// VRAM, a required render engine, required features like OIDN) against a
+// WorkerProfile, and best-fits on VRAM so large-memory workers stay free
+// for the tasks that actually need them.
+type DefaultWorkerSelector struct{}
+
+func (DefaultWorkerSelector) Ok(task *Task, w *WorkerProfile) bool {
+	reqs := task.Requirements()
+	if reqs == nil {
+		// No declared requirements - anything in the right queue can run it.
+		return true
+	}
+	if reqs.MinVramMb > w.VRAMMB {
+		return false
+	}
+	if reqs.RequiredEngine != "" && !w.Engines[reqs.RequiredEngine] {
+		return false
+	}
+	for _, feature := range reqs.RequiredFeatures {
+		if feature == "oidn" && !w.OIDN {
+			return false
+		}
+	}
+	return true
+}
+
+// Cmp prefers the smaller-VRAM worker of the two, so a task that only
+// needs 4GB doesn't tie up a 24GB node that a different queued task
+// actually requires.
+func (DefaultWorkerSelector) Cmp(task *Task, a, b *WorkerProfile) bool {
+	return a.VRAMMB < b.VRAMMB
+}
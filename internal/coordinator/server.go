@@ -3,26 +3,113 @@ package coordinator
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
+	"sync"
+	"time"
 
 	pb "github.com/skewer-project/skewer/api/proto/coordinator/v1"
 )
 
+// DefaultSubmissionTimeout is used when a SubmitJobRequest doesn't specify
+// one. A job still waiting to be picked up by a worker after this long is
+// almost certainly stuck behind a capacity problem, not slow rendering.
+const DefaultSubmissionTimeout = 15 * time.Minute
+
 // Server implements the gRPC CoordinatorService
 type Server struct {
 	pb.UnimplementedCoordinatorServiceServer
-	scheduler *Scheduler
+	scheduler Scheduler
 	manager   *CloudManager
 	tracker   *JobTracker
+	registry  *WorkerRegistry
+
+	// progressMu guards progressCounts, the recorded progress message count
+	// per TaskID. It is held in memory only and is not persisted across a
+	// coordinator restart. ReportTaskResult refuses to act on a
+	// terminal (success/failure) message until the count it carries matches
+	// what's been recorded here, so a MergeTask can never be dispatched
+	// while an earlier progress/log message for the same task is still in
+	// flight.
+	progressMu     sync.Mutex
+	progressCounts map[string]int32
+
+	// deadlineMu guards deadlineCancels, the cancel func for each in-flight
+	// task's deadline watcher (see watchDeadline). Armed by GetWorkStream
+	// when a task is handed out, disarmed by cancelDeadlineWatch as soon as
+	// ReportTaskResult gives a terminal answer for it.
+	deadlineMu      sync.Mutex
+	deadlineCancels map[string]context.CancelFunc
+
+	// reconcileMu guards reconcileSenders and reconcileMismatches.
+	reconcileMu sync.Mutex
+	// reconcileSenders holds the request channel for each session with a
+	// live ReconcileTasks stream open, so StartReconciler and killTask have
+	// somewhere to push a query or a forced cancellation - the coordinator
+	// never dials out to a worker itself, so this is the only way it can
+	// ask one anything between GetWorkStream dispatches.
+	reconcileSenders map[string]chan *pb.ReconcileRequest
+	// reconcileMismatches counts, per TaskID, how many consecutive
+	// reconcile rounds a worker and the scheduler have disagreed about it.
+	reconcileMismatches map[string]int32
 }
 
-func NewServer(scheduler *Scheduler, manager *CloudManager, tracker *JobTracker) *Server {
+func NewServer(scheduler Scheduler, manager *CloudManager, tracker *JobTracker, registry *WorkerRegistry) *Server {
 	return &Server{
-		scheduler: scheduler,
-		manager:   manager,
-		tracker:   tracker,
+		scheduler:           scheduler,
+		manager:             manager,
+		tracker:             tracker,
+		registry:            registry,
+		progressCounts:      make(map[string]int32),
+		deadlineCancels:     make(map[string]context.CancelFunc),
+		reconcileSenders:    make(map[string]chan *pb.ReconcileRequest),
+		reconcileMismatches: make(map[string]int32),
+	}
+}
+
+// Replay rebuilds the tracker's in-memory state from the JobStore (if one is
+// configured), re-enqueues any task that was dispatched to a worker but
+// never acknowledged, and re-arms the Scheduler's pause state for any job
+// that was paused before the crash - JobTracker.Replay only restores the
+// persisted Status, it has no access to the Scheduler to pause anything
+// itself. It must be called once, before GetWorkStream or SubmitJob traffic
+// is accepted.
+func (s *Server) Replay() error {
+	inFlight, pausedJobIDs, err := s.tracker.Replay()
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range inFlight {
+		payload, err := decodeTaskPayload(rec)
+		if err != nil {
+			log.Printf("[ERROR]: Dropping unreplayable in-flight task %s: %v", rec.ID, err)
+			continue
+		}
+		if _, err := s.scheduler.EnqueueTask(payload, rec.JobID, rec.FrameID, rec.Priority); err != nil {
+			log.Printf("[ERROR]: Failed to re-enqueue in-flight task %s: %v", rec.ID, err)
+			continue
+		}
+		// EnqueueTask gives the replayed task a fresh ID, so the record under
+		// the old ID is cleared here; otherwise it would never be removed and
+		// would be replayed again on every later restart.
+		if err := s.tracker.store.RemoveInFlightTask(rec.ID); err != nil {
+			log.Printf("[ERROR]: Failed to clear replayed in-flight task %s: %v", rec.ID, err)
+		}
+		log.Printf("[SERVER] Replayed in-flight task %s for job %s", rec.ID, rec.JobID)
+	}
+
+	if pausable, ok := s.scheduler.(Pausable); ok {
+		for _, jobID := range pausedJobIDs {
+			pausable.PauseJob(jobID)
+			log.Printf("[SERVER] Re-armed pause for job %s after replay", jobID)
+		}
+	} else if len(pausedJobIDs) > 0 {
+		log.Printf("[ERROR]: %d replayed job(s) were paused, but Scheduler %T doesn't support pausing - they'll dispatch as if never paused", len(pausedJobIDs), s.scheduler)
 	}
+
+	return nil
 }
 
 // ================= //
@@ -33,6 +120,14 @@ func (s *Server) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*pb.S
 	jobID := req.JobId // May make this a coordinator-generated ID
 	log.Printf("[COORDINATOR] Received SubmitJob request: %s", jobID)
 
+	// proto3 leaves an unset Priority as its zero value, not PriorityNormal -
+	// default it here so "didn't specify a priority" actually means "normal
+	// batch job" (chunk0-3/chunk1-1's documented baseline) rather than
+	// silently scoring below every task that did specify one.
+	if req.Priority <= 0 {
+		req.Priority = PriorityNormal
+	}
+
 	var newJob Job
 
 	// Route the job creation based on the user's requested payload
@@ -76,6 +171,8 @@ func (s *Server) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*pb.S
 		if err != nil {
 			return nil, err
 		}
+
+		s.startSubmissionTimeout(jobID, req)
 	}
 
 	return &pb.SubmitJobResponse{JobId: jobID}, nil
@@ -84,16 +181,15 @@ func (s *Server) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*pb.S
 func (s *Server) GetJobStatus(ctx context.Context, req *pb.GetJobStatusRequest) (*pb.GetJobStatusResponse, error) {
 	// Look up JobID in the JobTracker (if it exists)
 	job, err := s.tracker.GetJob(req.JobId)
-	errResponse := ""
 	if err != nil {
-		errResponse = err.Error()
+		return &pb.GetJobStatusResponse{ErrorMessage: err.Error()}, nil
 	}
 
 	// Return response. Interface handles progress automatically
 	return &pb.GetJobStatusResponse{
 		JobStatus:       job.GetStatus(),
 		ProgressPercent: job.Progress() * 100,
-		ErrorMessage:    errResponse,
+		ErrorMessage:    job.GetErrorMessage(),
 	}, nil
 }
 
@@ -115,28 +211,139 @@ func (s *Server) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.C
 	}, nil
 }
 
+// PauseJob halts dispatch of jobID's queued tasks. Tasks already out with a
+// worker are left to complete; their downstream enqueue (MergeTask, unlocked
+// dependents) is stalled until ResumeJob.
+func (s *Server) PauseJob(ctx context.Context, req *pb.PauseJobRequest) (*pb.PauseJobResponse, error) {
+	pausable, ok := s.scheduler.(Pausable)
+	if !ok {
+		return nil, fmt.Errorf("[ERROR] Configured scheduler backend does not support pausing jobs")
+	}
+
+	if err := s.tracker.SetStatus(req.JobId, pb.GetJobStatusResponse_JOB_STATUS_PAUSED); err != nil {
+		return nil, err
+	}
+	pausable.PauseJob(req.JobId)
+
+	return &pb.PauseJobResponse{Success: true}, nil
+}
+
+// ResumeJob lets jobID's tasks flow again, rehydrating anything the
+// scheduler withheld and flushing any completion/unlock work that was
+// stalled while the job was paused.
+func (s *Server) ResumeJob(ctx context.Context, req *pb.ResumeJobRequest) (*pb.ResumeJobResponse, error) {
+	pausable, ok := s.scheduler.(Pausable)
+	if !ok {
+		return nil, fmt.Errorf("[ERROR] Configured scheduler backend does not support pausing jobs")
+	}
+
+	if err := s.tracker.SetStatus(req.JobId, pb.GetJobStatusResponse_JOB_STATUS_QUEUED); err != nil {
+		return nil, err
+	}
+	pausable.ResumeJob(req.JobId)
+	s.tracker.FlushStalledWork(req.JobId)
+
+	return &pb.ResumeJobResponse{Success: true}, nil
+}
+
 // =====================================================================
 // INTERNAL API (Called by GKE Workers)
 // =====================================================================
 
+// Heartbeat lets a connected worker confirm its session is still alive and
+// report exactly which tasks it currently holds. StartSessionReaper uses
+// LastHeartbeat staleness - not per-task timers - to decide a session is
+// gone, so a worker only needs to heartbeat once per HeartbeatInterval no
+// matter how many tasks it's juggling.
+func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if s.registry == nil {
+		return &pb.HeartbeatResponse{Acknowledged: false}, fmt.Errorf("[ERROR] Coordinator has no WorkerRegistry configured")
+	}
+	if !s.registry.Heartbeat(req.SessionId, req.TaskIds) {
+		return &pb.HeartbeatResponse{Acknowledged: false}, fmt.Errorf("[ERROR] Unknown session %s; reconnect via GetWorkStream", req.SessionId)
+	}
+	return &pb.HeartbeatResponse{Acknowledged: true}, nil
+}
+
+// Drain marks a session so GetWorkStream stops handing it new tasks, so an
+// operator can cordon a node for maintenance without killing whatever it's
+// mid-render on - its stream ends the next time it asks for work rather
+// than mid-task.
+func (s *Server) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	if s.registry == nil || !s.registry.Drain(req.SessionId) {
+		return &pb.DrainResponse{Success: false}, fmt.Errorf("[ERROR] Unknown session %s", req.SessionId)
+	}
+	return &pb.DrainResponse{Success: true}, nil
+}
+
+// ListWorkers is an admin RPC reporting every live session and what it
+// currently holds. Useful for diagnosing "why is this job stuck" without
+// grepping coordinator logs.
+func (s *Server) ListWorkers(ctx context.Context, req *pb.ListWorkersRequest) (*pb.ListWorkersResponse, error) {
+	resp := &pb.ListWorkersResponse{}
+	if s.registry == nil {
+		return resp, nil
+	}
+
+	for _, sess := range s.registry.Snapshot() {
+		taskIDs := make([]string, 0, len(sess.TaskIDs))
+		for taskID := range sess.TaskIDs {
+			taskIDs = append(taskIDs, taskID)
+		}
+		resp.Workers = append(resp.Workers, &pb.WorkerStatus{
+			SessionId:     sess.SessionID,
+			WorkerId:      sess.WorkerID,
+			Capabilities:  sess.Capabilities,
+			LastSeenAt:    sess.LastHeartbeat.Unix(),
+			LeasedTaskIds: taskIDs,
+			Draining:      sess.Draining,
+		})
+	}
+	return resp, nil
+}
+
 // GetWorkStream - The KEY KEDA Pull Endpoint
 // Workers call this ONCE and hold the stream open to rapidly pull tasks.
 func (s *Server) GetWorkStream(req *pb.GetWorkStreamRequest, stream pb.CoordinatorService_GetWorkStreamServer) error {
 	workerID := req.WorkerId
 	capabilities := req.Capabilities
-	log.Printf("Worker %s connected. Capabilities: %v", workerID, capabilities)
+	profile := NewWorkerProfile(workerID, capabilities, req.VramMb, req.CpuCores, req.Engines, req.Os, req.Arch, req.Oidn)
+
+	// Mint a fresh session for this connection - a worker that reconnects
+	// after dropping out is always a new session with no memory of what it
+	// used to hold, so StartSessionReaper never has to guess whether an old
+	// heartbeat is still trustworthy.
+	var sessionID string
+	if s.registry != nil {
+		sessionID = s.registry.NewSession(workerID, capabilities)
+	}
+	log.Printf("Worker %s connected (session %s). Capabilities: %v", workerID, sessionID, capabilities)
 
 	for {
-		// Block and wait for the Scheduler to hand us a task.
-		// scheduler.GetNextTask accepts `capabilities` so it only
-		// hands Loom tasks to Loom workers, and Skewer tasks to Skewer workers.
-		task, err := s.scheduler.GetNextTask(stream.Context(), capabilities)
+		if s.registry != nil && s.registry.IsDraining(sessionID) {
+			log.Printf("[SERVER]: Session %s is draining. Ending stream for worker %s.", sessionID, workerID)
+			return nil
+		}
+
+		// Block and wait for the Scheduler to hand us a task. GetNextTask takes
+		// the full profile, not just capabilities, so it can hold back a task
+		// whose Requirements this worker can't meet (not enough VRAM, wrong
+		// engine, no OIDN) rather than handing it over and watching it fail.
+		task, err := s.scheduler.GetNextTask(stream.Context(), profile)
 		if err != nil {
 			// If the context is cancelled (worker disconnected), exit cleanly
 			log.Printf("[SERVER]: Worker %s stream closed: %v", workerID, err)
 			return err
 		}
 
+		// Drain may have landed while GetNextTask was blocked - hand the task
+		// back instead of sending it to a draining session.
+		if s.registry != nil && s.registry.IsDraining(sessionID) {
+			s.scheduler.RequeueTask(task.ID)
+			log.Printf("[SERVER]: Session %s is draining. Returned task %s and ending stream for worker %s.", sessionID, task.ID, workerID)
+			return nil
+		}
+
 		// Lazy cancellation check
 		job, err := s.tracker.GetJob(task.JobID)
 
@@ -154,6 +361,26 @@ func (s *Server) GetWorkStream(req *pb.GetWorkStreamRequest, stream pb.Coordinat
 			FrameId: task.FrameID,
 		}
 
+		// RedisScheduler tracks its own lease deadline per task; the Memory
+		// backend has no per-task lease anymore - staleness is judged by
+		// session heartbeat instead, so tell the worker when that'd trip.
+		if !task.LeaseDeadline.IsZero() {
+			workPackage.LeaseExpiresAt = task.LeaseDeadline.Unix()
+		} else {
+			workPackage.LeaseExpiresAt = time.Now().Add(HeartbeatInterval * MissedHeartbeatLimit).Unix()
+		}
+
+		// TaskDeadline tells the worker the execution budget it's been given
+		// (task.Timeout, or DefaultTaskTimeout) - independent of the lease,
+		// which is about worker liveness, not how long the render itself
+		// should take.
+		workPackage.TaskDeadline = task.Deadline.Unix()
+
+		if s.registry != nil {
+			s.registry.AttachTask(sessionID, task.ID)
+		}
+		s.scheduler.AttachSession(task.ID, sessionID)
+
 		// Type-assert the payload and map it to the Protobuf 'oneof'
 		switch t := task.Payload.(type) {
 		case *pb.RenderTask:
@@ -167,6 +394,14 @@ func (s *Server) GetWorkStream(req *pb.GetWorkStreamRequest, stream pb.Coordinat
 			continue
 		}
 
+		// Record the task as in-flight BEFORE sending it, so a crash between
+		// here and the worker's ack still has a durable record to replay.
+		if s.tracker.store != nil {
+			if err := s.tracker.store.SaveInFlightTask(task); err != nil {
+				log.Printf("[ERROR]: Failed to persist in-flight task %s: %v", task.ID, err)
+			}
+		}
+
 		// Send workPackage down the wire
 		if err := stream.Send(workPackage); err != nil {
 			log.Printf("[ERROR]: Failed to send task to worker %s: %v", workerID, err)
@@ -176,38 +411,122 @@ func (s *Server) GetWorkStream(req *pb.GetWorkStreamRequest, stream pb.Coordinat
 			return err
 		}
 
+		// Now that the task is actually on the wire, arm its deadline watch
+		// - mirroring asynq's per-task createContext, but on the coordinator
+		// side since the handler runs in a separate worker process we don't
+		// control.
+		s.watchDeadline(task)
+
+		// Disarms this job's submission timeout - a worker has now claimed
+		// at least one of its tasks, so it's no longer "stuck in the queue".
+		s.tracker.MarkStarted(task.JobID)
+
 		log.Printf("Assigned task %s to worker %s", task.ID, workerID)
 	}
 }
 
-func (s *Server) ReportTaskResult(ctx context.Context, req *pb.ReportTaskResultRequest) (*pb.ReportTaskResultResponse, error) {
+// ReportTaskResult is a worker-to-coordinator stream, one per in-flight
+// task: zero or more TaskProgress messages followed by exactly one
+// TaskResult (terminal). Because a single goroutine drains stream.Recv() in
+// order, every progress message is fully recorded - and acknowledged back
+// to the worker - before the terminal message is ever looked at, which is
+// what keeps a MergeTask from being dispatched while the final chunk's
+// upload notification is still in flight. PrecedingProgressCount is a
+// belt-and-suspenders check: it must match what we actually recorded, or
+// the terminal message is rejected rather than silently trusted.
+func (s *Server) ReportTaskResult(stream pb.CoordinatorService_ReportTaskResultServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch payload := req.Payload.(type) {
+		case *pb.ReportTaskResultRequest_Progress:
+			resp := s.recordProgress(payload.Progress)
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+
+		case *pb.ReportTaskResultRequest_Terminal:
+			resp, err := s.handleTerminalResult(payload.Terminal)
+			if sendErr := stream.Send(resp); sendErr != nil {
+				return sendErr
+			}
+			return err
+
+		default:
+			return fmt.Errorf("[ERROR] Unknown ReportTaskResult payload type %T", req.Payload)
+		}
+	}
+}
+
+// recordProgress notes, in memory only, that a progress message for TaskId
+// has been seen, so handleTerminalResult can verify nothing was skipped.
+func (s *Server) recordProgress(p *pb.TaskProgress) *pb.ReportTaskResultResponse {
+	s.progressMu.Lock()
+	s.progressCounts[p.TaskId] = p.SequenceNumber
+	s.progressMu.Unlock()
+
+	log.Printf("[SERVER] Task %s progress #%d from worker %s: %s", p.TaskId, p.SequenceNumber, p.WorkerId, p.Message)
+	return &pb.ReportTaskResultResponse{Acknowledged: true}
+}
+
+func (s *Server) handleTerminalResult(req *pb.TaskResult) (*pb.ReportTaskResultResponse, error) {
 	taskID := req.GetTaskId()
 	jobID := req.GetJobId()
 
+	s.progressMu.Lock()
+	seen := s.progressCounts[taskID]
+	delete(s.progressCounts, taskID)
+	s.progressMu.Unlock()
+
+	if seen != req.PrecedingProgressCount {
+		return &pb.ReportTaskResultResponse{Acknowledged: false}, fmt.Errorf(
+			"[ERROR] Task %s terminal result expected %d preceding progress messages, coordinator recorded %d",
+			taskID, req.PrecedingProgressCount, seen)
+	}
+
 	log.Printf("[SERVER] Task %s completed by worker %s: success=%v", taskID, req.WorkerId, req.Success)
 
-	// Tell the scheduler the worker is officially done with it (stops the Sweeper timeout)
-	task, exists := s.scheduler.MarkTaskComplete(taskID)
+	// The worker has given a terminal answer for this task, so its deadline
+	// watch no longer needs to fire.
+	s.cancelDeadlineWatch(taskID)
+	if s.registry != nil {
+		s.registry.ReleaseTask(taskID)
+	}
 
 	if !req.Success {
-		// Handle failure. Requeue the task, or increment a failure counter
-		// in the JobTracker and fail the whole job if it exceeds max retries.
-		if exists {
-			task.Retries++
-			if task.Retries > 3 {
-				log.Printf("[SERVER] Task %s failed too many times. Failing Job %s", taskID, jobID)
-				s.tracker.activeJobs[jobID].SetStatus(pb.GetJobStatusResponse_JOB_STATUS_FAILED)
-				s.scheduler.PurgeJobTasks(jobID) // Stop other tasks for this job
-			} else {
-				s.scheduler.RequeueTask(taskID)
-			}
-		} else {
-			// fmt.Errorf("[ERROR]: Task %s not found in active tasks", taskID)
+		// Route through the same classified-failure path as a missed
+		// heartbeat or an expired deadline, so MaxTaskRetries and
+		// dead-lettering are only ever decided in one place.
+		task, _ := s.failTask(taskID, NewTaskError(req.Retryable, req.ErrorMessage))
+		if task == nil {
 			return &pb.ReportTaskResultResponse{Acknowledged: false}, nil
 		}
+		if s.tracker.store != nil {
+			if err := s.tracker.store.RemoveInFlightTask(taskID); err != nil {
+				log.Printf("[ERROR]: Failed to clear in-flight task %s: %v", taskID, err)
+			}
+		}
 		return &pb.ReportTaskResultResponse{Acknowledged: true}, nil
 	}
 
+	// Tell the scheduler the worker is officially done with it (removes it from active tracking)
+	task, exists := s.scheduler.MarkTaskComplete(taskID)
+	if !exists {
+		return &pb.ReportTaskResultResponse{Acknowledged: false}, nil
+	}
+
+	if s.tracker.store != nil {
+		if err := s.tracker.store.RemoveInFlightTask(taskID); err != nil {
+			log.Printf("[ERROR]: Failed to clear in-flight task %s: %v", taskID, err)
+		}
+	}
+
 	// Update completed Tasks for the job
 	job, err := s.tracker.GetJob(task.JobID)
 	if err != nil {
@@ -224,15 +543,30 @@ func (s *Server) ReportTaskResult(ctx context.Context, req *pb.ReportTaskResultR
 		complete = jobType.CompletedTasks == jobType.TotalTasks
 
 		// Update the specific frame's progress
+		//
+		// TODO: jobType.Frames is nil for every RenderJob today, since
+		// handleRenderJobSubmit is still an unimplemented stub that never
+		// populates it (or enqueues any RenderTask in the first place) -
+		// the moment it's filled in, this lookup must also handle a
+		// missing FrameID instead of assuming frameState is non-nil.
 		frameState := jobType.Frames[task.FrameID]
 		frameState.CompletedChunks++
 
 		// Check if frame is complete
 		if frameState.CompletedChunks == frameState.TotalChunks {
-			log.Printf("Frame %s for job %s is fully rendered! Queuing MergeTask.", task.FrameID, jobID)
+			mergeTask, frameID := frameState.PendingMerge, task.FrameID
 
-			// NOW we hand it to the scheduler
-			s.scheduler.EnqueueTask(frameState.PendingMerge, jobID, task.FrameID)
+			if job.GetStatus() == pb.GetJobStatusResponse_JOB_STATUS_PAUSED {
+				// Let the chunk completion count, but hold the MergeTask
+				// back until ResumeJob flushes it.
+				log.Printf("Frame %s for job %s is fully rendered, but job is paused. Stalling MergeTask.", frameID, jobID)
+				s.tracker.StallWork(jobID, func() {
+					s.scheduler.EnqueueTask(mergeTask, jobID, frameID, task.Priority)
+				})
+			} else {
+				log.Printf("Frame %s for job %s is fully rendered! Queuing MergeTask.", frameID, jobID)
+				s.scheduler.EnqueueTask(mergeTask, jobID, frameID, task.Priority)
+			}
 
 			// Free up memory
 			frameState.PendingMerge = nil
@@ -246,33 +580,193 @@ func (s *Server) ReportTaskResult(ctx context.Context, req *pb.ReportTaskResultR
 		jobType.mu.Unlock()
 	}
 
+	// Persist the updated counters so a crash loses at most this one update.
+	if err := s.tracker.SaveProgress(job.ID()); err != nil {
+		log.Printf("[ERROR]: Failed to persist progress for job %s: %v", job.ID(), err)
+	}
+
 	// Queue downstream dependencies if job is complete
 	if complete {
-		job.SetStatus(pb.GetJobStatusResponse_JOB_STATUS_COMPLETED)
-
-		// Ask the tracker to safely update the math and report unlocked dependencies
-		unlockedJobs := s.tracker.UnlockDependencies(job.ID())
-
-		// Loop through whatever jobs just hit 0 dependencies and queue them
-		for _, newJob := range unlockedJobs {
-			// (You will eventually call s.handleCompositeJobSubmit here
-			// to actually queue the tasks for the newly unlocked job)
-			log.Printf("Job %s is fully unlocked and ready to queue!", newJob.ID())
-
-			// Type assert to figure out what kind of job just unlocked and queue it
-			req := newJob.GetOriginalReq()
-			switch typedJob := newJob.(type) {
-			case *RenderJob:
-				s.handleRenderJobSubmit(typedJob.ID(), req, req.GetRenderJob())
-			case *CompositeJob:
-				s.handleCompositeJobSubmit(typedJob.ID(), req, req.GetCompositeJob())
-			}
+		if job.GetStatus() == pb.GetJobStatusResponse_JOB_STATUS_PAUSED {
+			// The last task just finished while the job itself is paused.
+			// Don't flip to COMPLETED or unlock dependents yet - stall the
+			// whole transition for ResumeJob to replay.
+			log.Printf("Job %s finished its last task while paused. Stalling completion.", job.ID())
+			s.tracker.StallWork(job.ID(), func() { s.completeJob(job.ID()) })
+		} else {
+			s.completeJob(job.ID())
 		}
 	}
 
 	return &pb.ReportTaskResultResponse{Acknowledged: true}, nil
 }
 
+// completeJob marks jobID COMPLETED and queues tasks for any dependent job
+// that just had its last dependency satisfied. Split out of
+// ReportTaskResult so PauseJob/ResumeJob can stall and replay it.
+func (s *Server) completeJob(jobID string) {
+	s.tracker.SetStatus(jobID, pb.GetJobStatusResponse_JOB_STATUS_COMPLETED)
+
+	// Ask the tracker to safely update the math and report unlocked dependencies
+	unlockedJobs := s.tracker.UnlockDependencies(jobID)
+
+	// Loop through whatever jobs just hit 0 dependencies and queue them
+	for _, newJob := range unlockedJobs {
+		log.Printf("Job %s is fully unlocked and ready to queue!", newJob.ID())
+
+		// Type assert to figure out what kind of job just unlocked and queue it
+		req := newJob.GetOriginalReq()
+		switch typedJob := newJob.(type) {
+		case *RenderJob:
+			s.handleRenderJobSubmit(typedJob.ID(), req, req.GetRenderJob())
+		case *CompositeJob:
+			s.handleCompositeJobSubmit(typedJob.ID(), req, req.GetCompositeJob())
+		}
+
+		s.startSubmissionTimeout(newJob.ID(), req)
+	}
+}
+
+// startSubmissionTimeout arms a timer for jobID: if no worker has pulled a
+// task for it via GetWorkStream before the timer fires, the job - and every
+// job transitively depending on it - is failed with JOB_STATUS_TIMED_OUT
+// instead of leaving a downstream CompositeJob polling GetJobStatus forever
+// with no explanation.
+func (s *Server) startSubmissionTimeout(jobID string, req *pb.SubmitJobRequest) {
+	timeout := DefaultSubmissionTimeout
+	if req.SubmissionTimeoutSeconds > 0 {
+		timeout = time.Duration(req.SubmissionTimeoutSeconds) * time.Second
+	}
+
+	time.AfterFunc(timeout, func() {
+		if s.tracker.WasStarted(jobID) {
+			return
+		}
+		s.timeoutJob(jobID)
+	})
+}
+
+// timeoutJob fails jobID with JOB_STATUS_TIMED_OUT and cascades the failure
+// to every job transitively depending on it, each with a distinct message
+// pointing back at jobID as the actual cause.
+func (s *Server) timeoutJob(jobID string) {
+	msg := fmt.Sprintf("[ERROR] Job %s timed out waiting for a worker to pick up its first task", jobID)
+	if err := s.tracker.Fail(jobID, pb.GetJobStatusResponse_JOB_STATUS_TIMED_OUT, msg); err != nil {
+		log.Printf("[ERROR]: Failed to mark job %s timed out: %v", jobID, err)
+		return
+	}
+	s.scheduler.PurgeJobTasks(jobID)
+	log.Printf("[SERVER] Job %s timed out waiting for a worker. Cascading to dependents.", jobID)
+
+	cascadeMsg := fmt.Sprintf("[ERROR] Cancelled: upstream job %s timed out waiting for a worker", jobID)
+	for _, successorID := range s.tracker.Graph().GetTransitiveSuccessors(jobID) {
+		if err := s.tracker.Fail(successorID, pb.GetJobStatusResponse_JOB_STATUS_TIMED_OUT, cascadeMsg); err != nil {
+			log.Printf("[ERROR]: Failed to cascade-timeout dependent job %s: %v", successorID, err)
+			continue
+		}
+		s.scheduler.PurgeJobTasks(successorID)
+	}
+}
+
+// StartSessionReaper periodically checks the WorkerRegistry for sessions
+// that have missed too many heartbeats and reports everything each one was
+// holding as a retryable failure, all at once - a worker that vanishes
+// mid-render doesn't leave its other in-flight chunks stuck behind separate
+// per-task timeouts. A no-op if no WorkerRegistry was ever wired in.
+func (s *Server) StartSessionReaper(ctx context.Context, checkInterval time.Duration) {
+	if s.registry == nil {
+		return
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for sessionID, taskIDs := range s.registry.DeadSessions(time.Now()) {
+				log.Printf("[SERVER] Session %s missed %d heartbeats. Failing %d tasks.", sessionID, MissedHeartbeatLimit, len(taskIDs))
+				for _, taskID := range taskIDs {
+					s.cancelDeadlineWatch(taskID)
+					s.failTask(taskID, NewTaskError(true, "worker session missed heartbeats"))
+				}
+			}
+		}
+	}
+}
+
+// watchDeadline arms a context.WithDeadline bound to task.Deadline right as
+// GetWorkStream hands the task out, mirroring asynq's per-task
+// createContext: if ReportTaskResult hasn't given a terminal answer for it
+// by the time this fires, the worker's heartbeats can be perfectly healthy
+// and the task is still treated as a retryable timeout, via the same
+// failTask path StartSessionReaper uses.
+func (s *Server) watchDeadline(task *Task) {
+	ctx, cancel := context.WithDeadline(context.Background(), task.Deadline)
+
+	s.deadlineMu.Lock()
+	s.deadlineCancels[task.ID] = cancel
+	s.deadlineMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.deadlineMu.Lock()
+		_, stillArmed := s.deadlineCancels[task.ID]
+		delete(s.deadlineCancels, task.ID)
+		s.deadlineMu.Unlock()
+
+		if !stillArmed || ctx.Err() != context.DeadlineExceeded {
+			return // cancelDeadlineWatch beat the clock - task already resolved
+		}
+
+		log.Printf("[SERVER] Task %s exceeded its deadline (%s). Reporting a retryable timeout.", task.ID, task.Deadline)
+		s.failTask(task.ID, NewTaskError(true, "task exceeded its execution deadline"))
+	}()
+}
+
+// cancelDeadlineWatch disarms taskID's deadline watcher. Called as soon as
+// ReportTaskResult has a terminal answer for it, so a slow-but-successful
+// result can't race the timeout into double-reporting the same task.
+func (s *Server) cancelDeadlineWatch(taskID string) {
+	s.deadlineMu.Lock()
+	cancel, ok := s.deadlineCancels[taskID]
+	delete(s.deadlineCancels, taskID)
+	s.deadlineMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// failTask reports taskID's failure to the scheduler and, if that was the
+// failure that sent it to the dead-letter list for good, fails the owning
+// job and purges its other queued tasks - shared by every path that can
+// decide a task is done for a reason other than a worker-reported success:
+// handleTerminalResult, StartSessionReaper, watchDeadline, and
+// reconcileMismatch. Also clears progressCounts[taskID] - the paths other
+// than handleTerminalResult never go through ReportTaskResult at all, so
+// without this a stale count would sit here and spuriously reject a later,
+// legitimate terminal result for the same TaskID once the task is retried.
+func (s *Server) failTask(taskID string, taskErr TaskError) (task *Task, deadLettered bool) {
+	s.progressMu.Lock()
+	delete(s.progressCounts, taskID)
+	s.progressMu.Unlock()
+
+	task, deadLettered = s.scheduler.ReportTaskFailure(taskID, taskErr)
+	if task == nil || !deadLettered {
+		return task, deadLettered
+	}
+
+	log.Printf("[SERVER] Task %s dead-lettered (%s): %s. Failing Job %s", taskID, taskErr.Reason, taskErr.Detail, task.JobID)
+	if err := s.tracker.Fail(task.JobID, pb.GetJobStatusResponse_JOB_STATUS_FAILED, taskErr.Error()); err != nil {
+		log.Printf("[ERROR]: Failed to mark job %s failed after dead-lettering task %s: %v", task.JobID, taskID, err)
+	}
+	s.scheduler.PurgeJobTasks(task.JobID)
+	return task, deadLettered
+}
+
 // =====================================================================
 // STUBBED HELPERS
 // =====================================================================
@@ -283,20 +777,27 @@ func (s *Server) handleRenderJobSubmit(jobID string, req *pb.SubmitJobRequest, p
 	// Loop over params.SampleDivision.
 	// Create *pb.RenderTask objects.
 	// Call s.scheduler.EnqueueTask(...) for each chunk.
+	//
+	// TODO: this also needs to populate the new RenderJob's Frames map (one
+	// FrameState per frame, TotalChunks set from SampleDivision) before any
+	// task is enqueued - handleTerminalResult's RenderJob branch indexes
+	// straight into Frames[task.FrameID] with no nil check, so a RenderTask
+	// completing against a job built through this stub panics today.
 	return nil
 }
 
 func (s *Server) handleCompositeJobSubmit(jobID string, req *pb.SubmitJobRequest, params *pb.CompositeJobParams) error {
-	// TODO: Loop over req.NumFrames.
-	// Create *pb.CompositeTask objects.
-	// Call s.scheduler.EnqueueTask(...) for each frame.
-	for _, frame := range req. {
+	for i := int32(0); i < req.NumFrames; i++ {
+		frame := fmt.Sprintf("%d", i)
 		task := &pb.CompositeTask{
 			JobId:    jobID,
 			FrameId:  frame,
 			NumTasks: params.NumTasks,
+			Priority: req.Priority,
+		}
+		if _, err := s.scheduler.EnqueueTask(task, jobID, frame, req.Priority); err != nil {
+			return err
 		}
-		s.scheduler.EnqueueTask(task, jobID, frame)
 	}
 	return nil
 }
@@ -304,3 +805,274 @@ func (s *Server) handleCompositeJobSubmit(jobID string, req *pb.SubmitJobRequest
 // func generateJobID() string {
 // 	return "job-" + time.Now().Format("20060102150405")
 // }
+
+// ReconcileInterval is how often StartReconciler asks every worker with an
+// open ReconcileTasks stream to report its current task list - Mesos-style
+// implicit reconciliation, so scheduler/worker state drift surfaces well
+// before StartSessionReaper's full heartbeat timeout would catch it.
+const ReconcileInterval = 60 * time.Second
+
+// ReconcileMaxTries is how many consecutive reconcile rounds a task can go
+// on disagreeing about - the worker doesn't mention it, or explicitly
+// denies holding it - before Server gives up trusting either side's
+// bookkeeping and forces a KillTask.
+const ReconcileMaxTries = 3
+
+// ReconcileTasks is the bidirectional counterpart to GetWorkStream: a
+// worker opens it once, right alongside its work stream, and keeps it open
+// for the life of the connection. The coordinator never dials out to a
+// worker on its own, so this stream - not a call the coordinator could
+// place itself - is what StartReconciler and killTask use to ask a
+// specific worker anything between GetWorkStream dispatches.
+func (s *Server) ReconcileTasks(stream pb.CoordinatorService_ReconcileTasksServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	sessionID := first.SessionId
+	if sessionID == "" {
+		return fmt.Errorf("[ERROR] ReconcileTasks requires a SessionId from a prior GetWorkStream call")
+	}
+	s.handleReconcileResponse(sessionID, first)
+
+	send := make(chan *pb.ReconcileRequest, 1)
+	s.reconcileMu.Lock()
+	s.reconcileSenders[sessionID] = send
+	s.reconcileMu.Unlock()
+	defer func() {
+		s.reconcileMu.Lock()
+		delete(s.reconcileSenders, sessionID)
+		s.reconcileMu.Unlock()
+	}()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			s.handleReconcileResponse(sessionID, resp)
+		}
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case err := <-recvErr:
+			return err
+		case req := <-send:
+			if err := stream.Send(req); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StartReconciler periodically asks every session with an open
+// ReconcileTasks stream for its full current task list (implicit mode -
+// "report everything", as opposed to the narrower explicit query
+// requestExplicitReconcile sends about one disputed task). The actual
+// comparison happens in handleReconcileResponse once each reply lands. A
+// no-op if no WorkerRegistry was ever wired in.
+func (s *Server) StartReconciler(ctx context.Context, checkInterval time.Duration) {
+	if s.registry == nil {
+		return
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reconcileMu.Lock()
+			sessionIDs := make([]string, 0, len(s.reconcileSenders))
+			for sessionID := range s.reconcileSenders {
+				sessionIDs = append(sessionIDs, sessionID)
+			}
+			s.reconcileMu.Unlock()
+
+			for _, sessionID := range sessionIDs {
+				s.sendReconcileRequest(&pb.ReconcileRequest{SessionId: sessionID, Mode: pb.ReconcileRequest_IMPLICIT})
+			}
+		}
+	}
+}
+
+// requestExplicitReconcile asks sessionID to confirm, specifically, whether
+// it's still holding taskID - used to get a faster answer about one
+// disputed task instead of waiting for the next implicit sweep.
+func (s *Server) requestExplicitReconcile(sessionID, taskID string) {
+	s.sendReconcileRequest(&pb.ReconcileRequest{
+		SessionId: sessionID,
+		Mode:      pb.ReconcileRequest_EXPLICIT,
+		TaskIds:   []string{taskID},
+	})
+}
+
+// killTask pushes a forced-cancel instruction down sessionID's open
+// ReconcileTasks stream, for a task that's disagreed on for too many
+// reconcile rounds to trust either side's bookkeeping any further.
+func (s *Server) killTask(sessionID, taskID string) {
+	s.sendReconcileRequest(&pb.ReconcileRequest{
+		SessionId: sessionID,
+		Mode:      pb.ReconcileRequest_KILL,
+		TaskIds:   []string{taskID},
+	})
+}
+
+// sendReconcileRequest pushes req down sessionID's open ReconcileTasks
+// stream, if it has one. A silent no-op if the session never opened a
+// stream or already closed it, and a logged no-op if its channel is still
+// backed up from a previous request; neither is retried - the next periodic
+// round will try again.
+func (s *Server) sendReconcileRequest(req *pb.ReconcileRequest) {
+	s.reconcileMu.Lock()
+	send, ok := s.reconcileSenders[req.SessionId]
+	s.reconcileMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case send <- req:
+	default:
+		log.Printf("[SERVER] Dropped a %v reconcile request for session %s - its channel is still busy.", req.Mode, req.SessionId)
+	}
+}
+
+// handleReconcileResponse compares resp against what the scheduler and
+// job store believe sessionID holds. In implicit mode resp.TaskIds is the
+// worker's entire belief, compared against Scheduler.SessionSnapshot: tasks
+// the scheduler tracks but the worker didn't mention are requeued (or, past
+// ReconcileMaxTries, killed); tasks the worker mentions that the scheduler
+// has no record of are re-adopted from the job store if possible. In
+// explicit mode resp.TaskIds only covers the specific IDs that were asked
+// about, echoed back via resp.QueriedTaskIds.
+func (s *Server) handleReconcileResponse(sessionID string, resp *pb.ReconcileResponse) {
+	if resp.Mode == pb.ReconcileRequest_EXPLICIT {
+		s.reconcileExplicit(sessionID, resp)
+		return
+	}
+
+	reported := make(map[string]bool, len(resp.TaskIds))
+	for _, taskID := range resp.TaskIds {
+		reported[taskID] = true
+	}
+
+	tracked := s.scheduler.SessionSnapshot(sessionID)
+	trackedIDs := make(map[string]bool, len(tracked))
+	for _, task := range tracked {
+		trackedIDs[task.ID] = true
+	}
+
+	for _, task := range tracked {
+		if reported[task.ID] {
+			s.resolveReconcileMismatch(task.ID)
+			continue
+		}
+		s.reconcileMismatch(sessionID, task.ID)
+	}
+
+	for taskID := range reported {
+		if trackedIDs[taskID] {
+			continue
+		}
+		if s.reconcileAdopt(taskID, sessionID) {
+			log.Printf("[SERVER] Re-adopted task %s reported by session %s but missing from scheduler state.", taskID, sessionID)
+			continue
+		}
+		log.Printf("[SERVER] Session %s reports task %s, but neither the scheduler nor the job store knows it. Dropping.", sessionID, taskID)
+	}
+}
+
+// reconcileExplicit handles a worker's answer to requestExplicitReconcile:
+// any queried task it didn't confirm holding gets requeued right away.
+func (s *Server) reconcileExplicit(sessionID string, resp *pb.ReconcileResponse) {
+	held := make(map[string]bool, len(resp.TaskIds))
+	for _, taskID := range resp.TaskIds {
+		held[taskID] = true
+	}
+	for _, taskID := range resp.QueriedTaskIds {
+		if held[taskID] {
+			s.resolveReconcileMismatch(taskID)
+			continue
+		}
+		log.Printf("[SERVER] Session %s explicitly denied holding task %s. Requeuing.", sessionID, taskID)
+		s.scheduler.RequeueTask(taskID)
+		s.resolveReconcileMismatch(taskID)
+	}
+}
+
+// reconcileMismatch records another round of disagreement about taskID and
+// either requeues it (first time the worker stopped mentioning it) or, past
+// ReconcileMaxTries, kills it on the worker and fails it for good.
+func (s *Server) reconcileMismatch(sessionID, taskID string) {
+	mismatches := s.bumpReconcileMismatch(taskID)
+	if mismatches >= ReconcileMaxTries {
+		log.Printf("[SERVER] Task %s disagreed on for %d reconcile rounds. Forcing a kill.", taskID, mismatches)
+		s.killTask(sessionID, taskID)
+		s.cancelDeadlineWatch(taskID)
+		s.resolveReconcileMismatch(taskID)
+		s.failTask(taskID, NewTaskError(true, "reconciliation: worker never confirmed holding this task"))
+		return
+	}
+
+	log.Printf("[SERVER] Session %s didn't report task %s it's supposed to hold (try %d/%d). Requeuing.", sessionID, taskID, mismatches, ReconcileMaxTries)
+	s.scheduler.RequeueTask(taskID)
+	s.requestExplicitReconcile(sessionID, taskID)
+}
+
+func (s *Server) bumpReconcileMismatch(taskID string) int32 {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+	s.reconcileMismatches[taskID]++
+	return s.reconcileMismatches[taskID]
+}
+
+func (s *Server) resolveReconcileMismatch(taskID string) {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+	delete(s.reconcileMismatches, taskID)
+}
+
+// reconcileAdopt looks for taskID among the job store's in-flight records -
+// the coordinator's last durable bookkeeping for a task that was dispatched
+// but never acknowledged - and re-adopts it into the scheduler if found.
+// Returns false if there's no store configured or no matching record.
+func (s *Server) reconcileAdopt(taskID, sessionID string) bool {
+	if s.tracker.store == nil {
+		return false
+	}
+	recs, err := s.tracker.store.LoadInFlightTasks()
+	if err != nil {
+		log.Printf("[ERROR]: Failed to load in-flight tasks while reconciling %s: %v", taskID, err)
+		return false
+	}
+	for _, rec := range recs {
+		if rec.ID != taskID {
+			continue
+		}
+		payload, err := decodeTaskPayload(rec)
+		if err != nil {
+			log.Printf("[ERROR]: Failed to decode in-flight task %s while reconciling: %v", taskID, err)
+			return false
+		}
+		s.scheduler.AdoptTask(&Task{
+			ID:        rec.ID,
+			JobID:     rec.JobID,
+			FrameID:   rec.FrameID,
+			Payload:   payload,
+			CreatedAt: time.Now(),
+			Retries:   rec.Retries,
+			Priority:  rec.Priority,
+			Timeout:   taskTimeout(payload),
+			SessionID: sessionID,
+		})
+		return true
+	}
+	return false
+}
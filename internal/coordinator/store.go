@@ -0,0 +1,437 @@
+package coordinator
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pb "github.com/skewer-project/skewer/api/proto/coordinator/v1"
+)
+
+// StoreSchemaVersion is bumped whenever JobRecord (or the on-disk/row layout
+// that holds it) changes shape. Stores refuse to replay a version they don't
+// recognize rather than guess at a migration.
+const StoreSchemaVersion = 1
+
+// FrameRecord is the persisted twin of FrameState.
+type FrameRecord struct {
+	CompletedChunks int32
+	TotalChunks     int32
+	PendingMerge    *pb.MergeTask
+}
+
+// TaskRecord is the persisted twin of Task, used to replay in-flight work
+// that was handed to a worker but never acknowledged via ReportTaskResult.
+type TaskRecord struct {
+	ID       string
+	JobID    string
+	FrameID  string
+	Kind     string // "render", "merge", or "composite" - used to rebuild Payload
+	Payload  json.RawMessage
+	Retries  int32
+	Priority float64
+}
+
+// JobRecord is the durable representation of a Job plus whatever per-job
+// bookkeeping JobTracker needs to rebuild pendingDeps and the DAG on replay.
+type JobRecord struct {
+	SchemaVersion int
+
+	JobID        string
+	Kind         string // "render" or "composite"
+	Status       pb.GetJobStatusResponse_JobStatus
+	Dependencies []string
+	PendingDeps  int
+	ErrorMessage string
+
+	CompletedTasks int32
+	TotalTasks     int32
+	SampleDivision int32
+	Frames         map[string]*FrameRecord
+
+	CompletedFrames int32
+	TotalFrames     int32
+
+	OriginalReq *pb.SubmitJobRequest
+}
+
+// JobStore is the write-through persistence layer for JobTracker. Every
+// mutation that matters for crash recovery (job creation, status
+// transitions, dependency unlocking, per-frame progress) goes through here
+// right after JobTracker updates its in-memory view, mirroring the
+// fsjobqueue/dbjobqueue split in osbuild-composer: one implementation for a
+// single coordinator on a box, one for a coordinator that needs to survive
+// being rescheduled onto a different node entirely.
+type JobStore interface {
+	// SaveJob persists a brand-new job record.
+	SaveJob(rec *JobRecord) error
+
+	// UpdateStatus atomically transitions a job's status.
+	UpdateStatus(jobID string, status pb.GetJobStatusResponse_JobStatus) error
+
+	// UpdateProgress atomically persists CompletedTasks/CompletedFrames and
+	// per-frame chunk counts for a job.
+	UpdateProgress(rec *JobRecord) error
+
+	// UpdatePendingDeps atomically persists the live dependency countdown.
+	UpdatePendingDeps(jobID string, pendingDeps int) error
+
+	// SaveInFlightTask records a task the moment it's dispatched to a worker.
+	SaveInFlightTask(task *Task) error
+
+	// RemoveInFlightTask clears a task once ReportTaskResult has durably
+	// recorded its outcome.
+	RemoveInFlightTask(taskID string) error
+
+	// LoadAll returns every job record known to the store, for replay on
+	// coordinator startup.
+	LoadAll() ([]*JobRecord, error)
+
+	// LoadInFlightTasks returns every task that was dispatched but never
+	// acknowledged - these need to be re-enqueued after a crash.
+	LoadInFlightTasks() ([]*TaskRecord, error)
+}
+
+// ===================== //
+// * Filesystem backend * //
+// ===================== //
+
+// FSJobStore persists each job as a JSON file under dir/jobs and each
+// in-flight task as a JSON file under dir/tasks. It's the "dev box" store:
+// no external dependency, good enough for a single coordinator process.
+type FSJobStore struct {
+	dir string
+}
+
+func NewFSJobStore(dir string) (*FSJobStore, error) {
+	for _, sub := range []string{"jobs", "tasks"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to create %s dir: %w", sub, err)
+		}
+	}
+	return &FSJobStore{dir: dir}, nil
+}
+
+func (f *FSJobStore) jobPath(jobID string) string {
+	return filepath.Join(f.dir, "jobs", jobID+".json")
+}
+
+func (f *FSJobStore) taskPath(taskID string) string {
+	return filepath.Join(f.dir, "tasks", taskID+".json")
+}
+
+func (f *FSJobStore) writeJob(rec *JobRecord) error {
+	rec.SchemaVersion = StoreSchemaVersion
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to marshal job %s: %w", rec.JobID, err)
+	}
+	// Write to a temp file and rename so a crash mid-write never leaves a
+	// half-written job record behind.
+	tmp := f.jobPath(rec.JobID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("[STORE] Failed to write job %s: %w", rec.JobID, err)
+	}
+	return os.Rename(tmp, f.jobPath(rec.JobID))
+}
+
+func (f *FSJobStore) readJob(jobID string) (*JobRecord, error) {
+	data, err := os.ReadFile(f.jobPath(jobID))
+	if err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to read job %s: %w", jobID, err)
+	}
+	var rec JobRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to unmarshal job %s: %w", jobID, err)
+	}
+	return &rec, nil
+}
+
+func (f *FSJobStore) SaveJob(rec *JobRecord) error {
+	return f.writeJob(rec)
+}
+
+func (f *FSJobStore) UpdateStatus(jobID string, status pb.GetJobStatusResponse_JobStatus) error {
+	rec, err := f.readJob(jobID)
+	if err != nil {
+		return err
+	}
+	rec.Status = status
+	return f.writeJob(rec)
+}
+
+func (f *FSJobStore) UpdateProgress(rec *JobRecord) error {
+	return f.writeJob(rec)
+}
+
+func (f *FSJobStore) UpdatePendingDeps(jobID string, pendingDeps int) error {
+	rec, err := f.readJob(jobID)
+	if err != nil {
+		return err
+	}
+	rec.PendingDeps = pendingDeps
+	return f.writeJob(rec)
+}
+
+func (f *FSJobStore) SaveInFlightTask(task *Task) error {
+	kind, payload, err := encodeTaskPayload(task.Payload)
+	if err != nil {
+		return err
+	}
+	rec := &TaskRecord{
+		ID:       task.ID,
+		JobID:    task.JobID,
+		FrameID:  task.FrameID,
+		Kind:     kind,
+		Payload:  payload,
+		Retries:  task.Retries,
+		Priority: task.Priority,
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to marshal in-flight task %s: %w", task.ID, err)
+	}
+	return os.WriteFile(f.taskPath(task.ID), data, 0o644)
+}
+
+func (f *FSJobStore) RemoveInFlightTask(taskID string) error {
+	err := os.Remove(f.taskPath(taskID))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("[STORE] Failed to remove in-flight task %s: %w", taskID, err)
+	}
+	return nil
+}
+
+func (f *FSJobStore) LoadAll() ([]*JobRecord, error) {
+	entries, err := os.ReadDir(filepath.Join(f.dir, "jobs"))
+	if err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to list job records: %w", err)
+	}
+	var recs []*JobRecord
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		jobID := entry.Name()[:len(entry.Name())-len(".json")]
+		rec, err := f.readJob(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if rec.SchemaVersion != StoreSchemaVersion {
+			return nil, fmt.Errorf("[STORE] Job %s has schema version %d, coordinator expects %d", jobID, rec.SchemaVersion, StoreSchemaVersion)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func (f *FSJobStore) LoadInFlightTasks() ([]*TaskRecord, error) {
+	entries, err := os.ReadDir(filepath.Join(f.dir, "tasks"))
+	if err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to list in-flight tasks: %w", err)
+	}
+	var recs []*TaskRecord
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(f.dir, "tasks", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to read in-flight task %s: %w", entry.Name(), err)
+		}
+		var rec TaskRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to unmarshal in-flight task %s: %w", entry.Name(), err)
+		}
+		recs = append(recs, &rec)
+	}
+	return recs, nil
+}
+
+// ================== //
+// * Postgres backend * //
+// ================== //
+
+// PostgresJobStore is the production store: one row per job in `jobs`, one
+// row per in-flight task in `inflight_tasks`. Callers are expected to have
+// already run the schema migrations that create those tables.
+type PostgresJobStore struct {
+	db *sql.DB
+}
+
+func NewPostgresJobStore(db *sql.DB) (*PostgresJobStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("[STORE] Postgres store requires a non-nil *sql.DB")
+	}
+	return &PostgresJobStore{db: db}, nil
+}
+
+func (p *PostgresJobStore) SaveJob(rec *JobRecord) error {
+	rec.SchemaVersion = StoreSchemaVersion
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to marshal job %s: %w", rec.JobID, err)
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO jobs (job_id, schema_version, status, body)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_id) DO UPDATE SET schema_version = $2, status = $3, body = $4
+	`, rec.JobID, rec.SchemaVersion, int32(rec.Status), data)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to upsert job %s: %w", rec.JobID, err)
+	}
+	return nil
+}
+
+func (p *PostgresJobStore) readJob(jobID string) (*JobRecord, error) {
+	var data []byte
+	row := p.db.QueryRow(`SELECT body FROM jobs WHERE job_id = $1`, jobID)
+	if err := row.Scan(&data); err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to read job %s: %w", jobID, err)
+	}
+	var rec JobRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to unmarshal job %s: %w", jobID, err)
+	}
+	return &rec, nil
+}
+
+func (p *PostgresJobStore) UpdateStatus(jobID string, status pb.GetJobStatusResponse_JobStatus) error {
+	rec, err := p.readJob(jobID)
+	if err != nil {
+		return err
+	}
+	rec.Status = status
+	return p.SaveJob(rec)
+}
+
+func (p *PostgresJobStore) UpdateProgress(rec *JobRecord) error {
+	return p.SaveJob(rec)
+}
+
+func (p *PostgresJobStore) UpdatePendingDeps(jobID string, pendingDeps int) error {
+	rec, err := p.readJob(jobID)
+	if err != nil {
+		return err
+	}
+	rec.PendingDeps = pendingDeps
+	return p.SaveJob(rec)
+}
+
+func (p *PostgresJobStore) SaveInFlightTask(task *Task) error {
+	kind, payload, err := encodeTaskPayload(task.Payload)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO inflight_tasks (task_id, job_id, frame_id, kind, payload, retries, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (task_id) DO UPDATE SET retries = $6, priority = $7
+	`, task.ID, task.JobID, task.FrameID, kind, []byte(payload), task.Retries, task.Priority)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to upsert in-flight task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+func (p *PostgresJobStore) RemoveInFlightTask(taskID string) error {
+	_, err := p.db.Exec(`DELETE FROM inflight_tasks WHERE task_id = $1`, taskID)
+	if err != nil {
+		return fmt.Errorf("[STORE] Failed to delete in-flight task %s: %w", taskID, err)
+	}
+	return nil
+}
+
+func (p *PostgresJobStore) LoadAll() ([]*JobRecord, error) {
+	rows, err := p.db.Query(`SELECT body, schema_version FROM jobs`)
+	if err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var recs []*JobRecord
+	for rows.Next() {
+		var data []byte
+		var schemaVersion int
+		if err := rows.Scan(&data, &schemaVersion); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to scan job row: %w", err)
+		}
+		if schemaVersion != StoreSchemaVersion {
+			return nil, fmt.Errorf("[STORE] Job row has schema version %d, coordinator expects %d", schemaVersion, StoreSchemaVersion)
+		}
+		var rec JobRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to unmarshal job row: %w", err)
+		}
+		recs = append(recs, &rec)
+	}
+	return recs, rows.Err()
+}
+
+func (p *PostgresJobStore) LoadInFlightTasks() ([]*TaskRecord, error) {
+	rows, err := p.db.Query(`SELECT task_id, job_id, frame_id, kind, payload, retries, priority FROM inflight_tasks`)
+	if err != nil {
+		return nil, fmt.Errorf("[STORE] Failed to query in-flight tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var recs []*TaskRecord
+	for rows.Next() {
+		rec := &TaskRecord{}
+		if err := rows.Scan(&rec.ID, &rec.JobID, &rec.FrameID, &rec.Kind, &rec.Payload, &rec.Retries, &rec.Priority); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to scan in-flight task row: %w", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, rows.Err()
+}
+
+// encodeTaskPayload captures which oneof variant a Task's Payload holds so
+// it can be reconstructed by decodeTaskPayload after a restart.
+func encodeTaskPayload(payload any) (kind string, data json.RawMessage, err error) {
+	switch t := payload.(type) {
+	case *pb.RenderTask:
+		kind = "render"
+		data, err = json.Marshal(t)
+	case *pb.MergeTask:
+		kind = "merge"
+		data, err = json.Marshal(t)
+	case *pb.CompositeTask:
+		kind = "composite"
+		data, err = json.Marshal(t)
+	default:
+		return "", nil, fmt.Errorf("[STORE] Unknown task payload type %T", payload)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("[STORE] Failed to marshal %s task payload: %w", kind, err)
+	}
+	return kind, data, nil
+}
+
+// decodeTaskPayload is the inverse of encodeTaskPayload, used during replay.
+func decodeTaskPayload(rec *TaskRecord) (any, error) {
+	switch rec.Kind {
+	case "render":
+		var t pb.RenderTask
+		if err := json.Unmarshal(rec.Payload, &t); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to unmarshal render task %s: %w", rec.ID, err)
+		}
+		return &t, nil
+	case "merge":
+		var t pb.MergeTask
+		if err := json.Unmarshal(rec.Payload, &t); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to unmarshal merge task %s: %w", rec.ID, err)
+		}
+		return &t, nil
+	case "composite":
+		var t pb.CompositeTask
+		if err := json.Unmarshal(rec.Payload, &t); err != nil {
+			return nil, fmt.Errorf("[STORE] Failed to unmarshal composite task %s: %w", rec.ID, err)
+		}
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("[STORE] Unknown task kind %q for task %s", rec.Kind, rec.ID)
+	}
+}